@@ -0,0 +1,141 @@
+package jdenticon
+
+import (
+	"encoding/hex"
+	"math/bits"
+	"strconv"
+)
+
+// Algorithm selects how the hash is mapped to the features of an identicon.
+type Algorithm int
+
+const (
+	// AlgorithmClassic reads every feature from a single hex digit of the
+	// hash, compatible with Jdenticon. The modulo by the number of shapes and
+	// colors favors the first ones: of the 14 inner shapes two are twice as
+	// likely, and the dark gray is more likely than the other theme colors.
+	AlgorithmClassic Algorithm = iota
+	// AlgorithmUniform reads every feature from its own bit field of the
+	// digest and rejects values out of range instead of taking a modulo, so
+	// every shape, rotation and color is equally likely. A field that runs
+	// out of bits continues in a hash of its own, so no feature depends on
+	// another one. The fields of the 160 bit digest are:
+	//
+	//	bits 0-27    the hue
+	//	bits 28-63   the three layer colors, 12 bits each
+	//	bits 64-159  the shapes and rotations read from hash digit positions
+	//	             0 to 11, 8 bits each; higher positions use their own hash
+	//	bits 64-     the blocks of StyleBlocks
+	//
+	// Shapes are picked from the bits through ShapeSet, so appending shapes
+	// to a set keeps the other features of all identities.
+	//
+	// With the classic layout and the built-in shapes, the entropy of the
+	// shapes and rotations grows from 11.75 to 11.81 bits and the one of the
+	// layer colors from 4.81 to 4.85 bits, 16.66 bits in all against 16.56.
+	// Both algorithms reach the same 132608 combinations (2^17.0); beyond
+	// that the icons are limited by the shapes, not by the hash. The hue
+	// keeps 28 bits, of which about log2(FingerprintHues) = 4.6 bits are
+	// visible.
+	AlgorithmUniform
+)
+
+// bit fields of AlgorithmUniform
+const (
+	uniformHueBits    = 28
+	uniformColorStart = uniformHueBits
+	uniformColorBits  = 12
+	uniformDigitStart = uniformColorStart + 3*uniformColorBits
+	uniformDigitBits  = 8
+	uniformDigits     = (4*hashDigits - uniformDigitStart) / uniformDigitBits
+)
+
+// hashSampler reads the bits of a field of the hash, most significant first,
+// then the bits of a hash of the field label, extended by hashing it again.
+type hashSampler struct {
+	hash  string
+	label string
+	bytes []byte
+	pos   int
+	end   int
+}
+
+// newHashSampler returns a sampler of the width bits of the hash from offset.
+func newHashSampler(hash string, offset, width int, label string) *hashSampler {
+	b, _ := hex.DecodeString(hash)
+	return &hashSampler{hash: hash, label: label, bytes: b, pos: offset, end: offset + width}
+}
+
+// read returns the next n bits, at most 32.
+func (s *hashSampler) read(n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		if s.pos == s.end {
+			if s.label != "" {
+				s.hash, s.label = hashIdentity(s.hash+":"+s.label), ""
+			} else {
+				s.hash = hashIdentity(s.hash)
+			}
+			s.bytes, _ = hex.DecodeString(s.hash)
+			s.pos, s.end = 0, 8*len(s.bytes)
+		}
+		bit := s.bytes[s.pos/8] >> uint(7-s.pos%8) & 1
+		v = v<<1 | uint32(bit)
+		s.pos++
+	}
+	return v
+}
+
+// uniform returns a value from 0 to n-1 with equal probability, by reading
+// just enough bits and trying again when the value is out of range.
+func (s *hashSampler) uniform(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	k := bits.Len(uint(n - 1))
+	for {
+		if v := int(s.read(k)); v < n {
+			return v
+		}
+	}
+}
+
+// uniform reports whether the features are read with AlgorithmUniform.
+func (j *jdenticon) uniform() bool {
+	return j.config.Algorithm == AlgorithmUniform
+}
+
+// uniformHue returns the 28 bits of the hue, which also pick palette colors.
+func (j *jdenticon) uniformHue() int64 {
+	return int64(newHashSampler(j.hash, 0, uniformHueBits, "hue").read(uniformHueBits))
+}
+
+// uniformColor returns the theme index of the layer color before the
+// deduplication of colors().
+func (j *jdenticon) uniformColor(layer, themeSize int) int {
+	offset := uniformColorStart + layer*uniformColorBits
+	return newHashSampler(j.hash, offset, uniformColorBits, "color "+strconv.Itoa(layer)).uniform(themeSize)
+}
+
+// uniformDigit returns the sampler of the field standing for the hash digit
+// position of the classic algorithm.
+func (j *jdenticon) uniformDigit(index int) *hashSampler {
+	label := "digit " + strconv.Itoa(index)
+	if index >= uniformDigits {
+		return newHashSampler(j.hash, 0, 0, label)
+	}
+	return newHashSampler(j.hash, uniformDigitStart+index*uniformDigitBits, uniformDigitBits, label)
+}
+
+// blockBits returns the n blocks of the left half of StyleBlocks.
+func (j *jdenticon) blockBits(n int) []bool {
+	if !j.uniform() {
+		return hashBits(j.hash, blocksDigit, n)
+	}
+	s := newHashSampler(j.hash, uniformDigitStart, 4*hashDigits-uniformDigitStart, "")
+	result := make([]bool, n)
+	for i := range result {
+		result[i] = s.read(1) == 1
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package jdenticon
+
+import (
+	"strconv"
+	"testing"
+)
+
+// chiSquare returns the chi-square statistic of the counts against a uniform
+// distribution.
+func chiSquare(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+	sum := 0.0
+	for _, n := range counts {
+		d := float64(n) - expected
+		sum += d * d / expected
+	}
+	return sum
+}
+
+// TestAlgorithmUniform checks every feature of AlgorithmUniform against the
+// chi-square critical value at p = 0.001.
+func TestAlgorithmUniform(t *testing.T) {
+	const identities = 20000
+	c := &Config{Hues: -1, Algorithm: AlgorithmUniform}
+	tests := []struct {
+		name     string
+		buckets  int
+		critical float64
+		feature  func(j *jdenticon) int
+	}{
+		{"hue", FingerprintHues, 49.73, func(j *jdenticon) int {
+			return int(j.hue()*FingerprintHues) % FingerprintHues
+		}},
+		{"outer shape", len(OuterShapes), 16.27, func(j *jdenticon) int {
+			return j.shape(OuterShapes, 2)
+		}},
+		{"inner shape", len(InnerShapes), 34.53, func(j *jdenticon) int {
+			return j.shape(InnerShapes, 1)
+		}},
+		{"high digit shape", len(InnerShapes), 34.53, func(j *jdenticon) int {
+			return j.shape(InnerShapes, 30)
+		}},
+		{"rotation", 4, 16.27, func(j *jdenticon) int {
+			return j.rotation(3)
+		}},
+		// the first layer color comes before the deduplication of colors()
+		{"color", 5, 18.47, func(j *jdenticon) int {
+			return j.colorIndexes(5)[0]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make([]int, tt.buckets)
+			for i := 0; i < identities; i++ {
+				j := &jdenticon{config: c, hash: hashIdentity(strconv.Itoa(i))}
+				counts[tt.feature(j)]++
+			}
+			if x := chiSquare(counts, identities); x > tt.critical {
+				t.Errorf("chi-square = %.2f, want at most %.2f: %v", x, tt.critical, counts)
+			}
+		})
+	}
+}
+
+func TestAlgorithmUniformBlocks(t *testing.T) {
+	const identities = 5000
+	c := &Config{Style: StyleBlocks, Algorithm: AlgorithmUniform}
+	counts := make([]int, 2)
+	for i := 0; i < identities; i++ {
+		for _, on := range Fingerprint(strconv.Itoa(i), c).Blocks {
+			if on {
+				counts[1]++
+			} else {
+				counts[0]++
+			}
+		}
+	}
+	if x := chiSquare(counts, counts[0]+counts[1]); x > 10.83 {
+		t.Errorf("chi-square = %.2f, want at most 10.83: %v", x, counts)
+	}
+}
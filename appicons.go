@@ -0,0 +1,163 @@
+package jdenticon
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Safe zones of the platform icon formats, as the fraction of the icon edge
+// that is guaranteed to stay visible after masking.
+const (
+	// Android adaptive icons are 108dp with a 66dp safe circle
+	adaptiveSafeZone = 66.0 / 108
+	// maskable PWA icons keep a centered circle of 80% diameter; the square
+	// grid of the identicon has to fit inside it
+	maskableSafeZone = 0.8 / math.Sqrt2
+)
+
+// appIcon is a single file of the app icon set.
+type appIcon struct {
+	name    string
+	size    int
+	purpose string
+	render  func(hash string, c *Config, size int) (image.Image, error)
+}
+
+// appIcons lists the files written by WriteAppIcons.
+var appIcons = []appIcon{ // nolint:gochecknoglobals
+	{name: "apple-touch-icon.png", size: 180, render: renderOpaqueIcon},
+	{name: "android/ic_launcher_foreground.png", size: 432, render: renderAdaptiveForeground},
+	{name: "android/ic_launcher_background.png", size: 432, render: renderAdaptiveBackground},
+	{name: "icon-192.png", size: 192, purpose: "any", render: renderTransparentIcon},
+	{name: "icon-512.png", size: 512, purpose: "any", render: renderTransparentIcon},
+	{name: "maskable-192.png", size: 192, purpose: "maskable", render: renderMaskableIcon},
+	{name: "maskable-512.png", size: 512, purpose: "maskable", render: renderMaskableIcon},
+}
+
+const adaptiveIconXML = `<?xml version="1.0" encoding="utf-8"?>
+<adaptive-icon xmlns:android="http://schemas.android.com/apk/res/android">
+    <background android:drawable="@drawable/ic_launcher_background" />
+    <foreground android:drawable="@drawable/ic_launcher_foreground" />
+</adaptive-icon>
+`
+
+type manifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+type manifestFragment struct {
+	Icons           []manifestIcon `json:"icons"`
+	BackgroundColor string         `json:"background_color"`
+}
+
+// WriteAppIcons writes the app icon set of the identity to dir: an Apple
+// touch icon, the Android adaptive icon layers, PWA icons including maskable
+// ones and a manifest.json fragment referencing them. Config.Padding is kept
+// inside the platform safe zones and Config.Background is used wherever the
+// platform requires an opaque icon.
+func WriteAppIcons(dir string, identity string, c *Config) error {
+	hash := c.hashIdentity(identity)
+	manifest := manifestFragment{
+		Icons:           []manifestIcon{},
+		BackgroundColor: toHex(opaqueBackground(c.Background)),
+	}
+	for _, icon := range appIcons {
+		name := filepath.Join(dir, filepath.FromSlash(icon.name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		img, err := icon.render(hash, c, icon.size)
+		if err != nil {
+			return err
+		}
+		if err := writePNG(name, img); err != nil {
+			return err
+		}
+		if icon.purpose != "" {
+			manifest.Icons = append(manifest.Icons, manifestIcon{
+				Src:     icon.name,
+				Sizes:   fmt.Sprintf("%dx%d", icon.size, icon.size),
+				Type:    "image/png",
+				Purpose: icon.purpose,
+			})
+		}
+	}
+	xml := filepath.Join(dir, "android", "ic_launcher.xml")
+	if err := ioutil.WriteFile(xml, []byte(adaptiveIconXML), 0644); err != nil { // nolint:gosec
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "manifest.json"), append(data, '\n'), 0644) // nolint:gosec
+}
+
+func writePNG(name string, img image.Image) (err error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return png.Encode(f, img)
+}
+
+// appIconConfig copies c for a square icon of the given size whose content is
+// shrunk to the safe fraction of the edge.
+func appIconConfig(c *Config, size int, safe float64, background color.Color) *Config {
+	cc := *c
+	cc.Width = size
+	cc.Height = size
+	cc.Padding = (1-safe)/2 + c.Padding*safe
+	cc.Background = background
+	return &cc
+}
+
+func renderAppIcon(hash string, c *Config) (image.Image, error) {
+	return newJdenticon(hash, c).rasterize()
+}
+
+func renderOpaqueIcon(hash string, c *Config, size int) (image.Image, error) {
+	return renderAppIcon(hash, appIconConfig(c, size, 1, opaqueBackground(c.Background)))
+}
+
+func renderTransparentIcon(hash string, c *Config, size int) (image.Image, error) {
+	return renderAppIcon(hash, appIconConfig(c, size, 1, c.Background))
+}
+
+func renderMaskableIcon(hash string, c *Config, size int) (image.Image, error) {
+	return renderAppIcon(hash, appIconConfig(c, size, maskableSafeZone, opaqueBackground(c.Background)))
+}
+
+func renderAdaptiveForeground(hash string, c *Config, size int) (image.Image, error) {
+	return renderAppIcon(hash, appIconConfig(c, size, adaptiveSafeZone, color.Transparent))
+}
+
+func renderAdaptiveBackground(hash string, c *Config, size int) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opaqueBackground(c.Background)), image.Point{}, draw.Src)
+	return img, nil
+}
+
+// opaqueBackground composes the background over white, since platforms
+// render transparent pixels of opaque icon slots black.
+func opaqueBackground(c color.Color) color.Color {
+	r, g, b, a := c.RGBA()
+	white := 0xffff - a
+	return color.RGBA64{uint16(r + white), uint16(g + white), uint16(b + white), 0xffff}
+}
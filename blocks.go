@@ -0,0 +1,113 @@
+package jdenticon
+
+import (
+	"math"
+)
+
+// Style selects how an identicon is drawn.
+type Style int
+
+const (
+	// StyleGeometric draws the shapes of Jdenticon.
+	StyleGeometric Style = iota
+	// StyleBlocks draws a grid of filled squares mirrored left to right, like
+	// the identicons of GitHub.
+	StyleBlocks
+)
+
+// DefaultBlocks is the number of blocks along each edge in StyleBlocks.
+const DefaultBlocks = 5
+
+// first hash digit used for the blocks; the digits before select the colors
+const blocksDigit = 11
+
+// renderBlocks draws the blocks in the main color of the theme. The block
+// size is rounded down to whole pixels, so the icon stays crisp.
+func (j *jdenticon) renderBlocks() {
+	n := j.config.Blocks
+	if n <= 0 {
+		n = DefaultBlocks
+	}
+	side := math.Min(j.geometry.X, j.geometry.Y) * (1 - 2*j.config.padding())
+	cell := math.Max(1, math.Floor(side/float64(n)))
+	origin := Point{
+		X: math.Floor((j.geometry.X - cell*float64(n)) / 2),
+		Y: math.Floor((j.geometry.Y - cell*float64(n)) / 2),
+	}
+
+	half := (n + 1) / 2
+	bits := j.blockBits(n * half)
+	filled := make([][]bool, n)
+	for row := range filled {
+		filled[row] = make([]bool, n)
+		for col := 0; col < half; col++ {
+			on := bits[row*half+col]
+			filled[row][col] = on
+			filled[row][n-1-col] = on
+		}
+	}
+
+	shapes := Shapes{}
+	for _, r := range mergeBlocks(filled) {
+		shapes = append(shapes, newRectangle(
+			origin.X+float64(r[0])*cell,
+			origin.Y+float64(r[1])*cell,
+			float64(r[2])*cell,
+			float64(r[3])*cell,
+			false,
+		))
+	}
+	if len(shapes) > 0 {
+		j.svg.Paths = append(j.svg.Paths, Path{
+			Fill:   j.theme()[1],
+			Shapes: shapes,
+		})
+	}
+}
+
+// mergeBlocks covers the filled blocks with as few rectangles as runs allow:
+// horizontal runs of every row are merged with identical runs of the rows
+// below. Rectangles are returned as column, row, width and height.
+func mergeBlocks(filled [][]bool) [][4]int {
+	result := [][4]int{}
+	open := map[[2]int]int{} // run start and end to index in result
+	for row := range filled {
+		next := map[[2]int]int{}
+		for col := 0; col < len(filled[row]); {
+			if !filled[row][col] {
+				col++
+				continue
+			}
+			end := col
+			for end < len(filled[row]) && filled[row][end] {
+				end++
+			}
+			run := [2]int{col, end}
+			if idx, ok := open[run]; ok {
+				result[idx][3]++
+				next[run] = idx
+			} else {
+				next[run] = len(result)
+				result = append(result, [4]int{col, row, end - col, 1})
+			}
+			col = end
+		}
+		open = next
+	}
+	return result
+}
+
+// hashBits returns n bits read from the hash digits starting at pos. The
+// hash is extended by hashing it again when more bits are needed.
+func hashBits(hash string, pos, n int) []bool {
+	digits := hash[pos:]
+	for len(digits)*4 < n {
+		hash = hashIdentity(hash)
+		digits += hash
+	}
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = hexDigits(digits, i/4, 1)&(1<<uint(i%4)) != 0
+	}
+	return bits
+}
@@ -0,0 +1,357 @@
+// Command jdenticon renders identicons from the command line.
+//
+// Usage:
+//
+//	jdenticon <command> [flags] <identity>
+//
+// The commands are:
+//
+//	svg       write the icon as SVG
+//	png       write the icon as PNG
+//	ico       write a multi-resolution favicon
+//	appicons  write the app icon set to a directory
+//	term      print the icon to the terminal
+//	theme     write the colors of the icon as JSON
+//	collisions report identities with the same icons, with -near also similar ones
+//
+// The -near scan of collisions compares every pair of distinct icons, so its
+// time grows with the square of their number: fine for thousands of
+// identities, slow for millions.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	jdenticon "github.com/nsemikov/jdenticon-go"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+// nolint:gochecknoglobals
+var commands = []command{
+	{"svg", "write the icon as SVG", runSVG},
+	{"png", "write the icon as PNG", runPNG},
+	{"ico", "write a multi-resolution favicon", runICO},
+	{"appicons", "write the app icon set to a directory", runAppIcons},
+	{"term", "print the icon to the terminal", runTerm},
+	{"theme", "write the colors of the icon as JSON", runTheme},
+	{"collisions", "report identities with the same icons, with -near also similar ones", runCollisions},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	_, _ = fmt.Fprintln(os.Stderr, "usage: jdenticon <command> [flags] <identity>")
+	_, _ = fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+// options holds the flags shared by all commands.
+type options struct {
+	flags   *flag.FlagSet
+	size    int
+	config  string
+	palette string
+	variant string
+	salt    int
+	output  string
+}
+
+// newOptions registers the shared flags, with size as the default icon size.
+func newOptions(name string, size int) *options {
+	o := &options{flags: flag.NewFlagSet(name, flag.ExitOnError)}
+	o.flags.IntVar(&o.size, "size", size, "icon size in pixels")
+	o.flags.StringVar(&o.config, "config", "", "config string, see jdenticon.ConfigFromString")
+	o.flags.StringVar(&o.palette, "palette", "", "built-in palette: material, tailwind, pastel or high-contrast")
+	o.flags.StringVar(&o.variant, "variant", "light", "color variant: light or dark")
+	o.flags.IntVar(&o.salt, "salt", 0, "reroll the icon of the identity, 0 for the original one")
+	o.flags.StringVar(&o.output, "o", "-", "output file, - for stdout")
+	return o
+}
+
+// parse parses the flags and returns the identity.
+func (o *options) parse(args []string) (string, error) {
+	if err := o.flags.Parse(args); err != nil {
+		return "", err
+	}
+	if o.flags.NArg() != 1 {
+		return "", fmt.Errorf("%s: expected exactly one identity", o.flags.Name())
+	}
+	return o.flags.Arg(0), nil
+}
+
+func (o *options) configuration() (*jdenticon.Config, error) {
+	c := *jdenticon.DefaultConfig
+	if o.config != "" {
+		parsed, err := jdenticon.ConfigFromString(o.config)
+		if err != nil {
+			return nil, err
+		}
+		c = *parsed
+	}
+	if o.palette != "" {
+		p, err := jdenticon.PaletteByName(o.palette)
+		if err != nil {
+			return nil, err
+		}
+		c.Palette = p
+	}
+	variant, err := jdenticon.ParseVariant(o.variant)
+	if err != nil {
+		return nil, err
+	}
+	c.Variant = variant
+	c.Salt = o.salt
+	c.Width = o.size
+	c.Height = o.size
+	return &c, nil
+}
+
+// icon is an identicon with all output formats.
+type icon interface {
+	jdenticon.Jdenticon
+	jdenticon.Encoder
+	jdenticon.Printer
+}
+
+func (o *options) icon(args []string) (icon, error) {
+	identity, err := o.parse(args)
+	if err != nil {
+		return nil, err
+	}
+	c, err := o.configuration()
+	if err != nil {
+		return nil, err
+	}
+	return jdenticon.NewWithConfig(identity, c).(icon), nil
+}
+
+// write calls fn with the output file or stdout.
+func (o *options) write(fn func(w io.Writer) error) (err error) {
+	if o.output == "-" {
+		return fn(os.Stdout)
+	}
+	f, err := os.Create(o.output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return fn(f)
+}
+
+func runSVG(args []string) error {
+	o := newOptions("svg", jdenticon.DefaultConfig.Width)
+	icon, err := o.icon(args)
+	if err != nil {
+		return err
+	}
+	return o.write(func(w io.Writer) error {
+		svg, err := icon.SVG()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(svg)
+		return err
+	})
+}
+
+func runTheme(args []string) error {
+	o := newOptions("theme", jdenticon.DefaultConfig.Width)
+	identity, err := o.parse(args)
+	if err != nil {
+		return err
+	}
+	c, err := o.configuration()
+	if err != nil {
+		return err
+	}
+	return o.write(func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jdenticon.Theme(identity, c))
+	})
+}
+
+func runPNG(args []string) error {
+	o := newOptions("png", jdenticon.DefaultConfig.Width)
+	icon, err := o.icon(args)
+	if err != nil {
+		return err
+	}
+	return o.write(icon.PNG)
+}
+
+func runICO(args []string) error {
+	o := newOptions("ico", jdenticon.DefaultConfig.Width)
+	icon, err := o.icon(args)
+	if err != nil {
+		return err
+	}
+	return o.write(func(w io.Writer) error {
+		return icon.ICO(w)
+	})
+}
+
+func runAppIcons(args []string) error {
+	o := newOptions("appicons", jdenticon.DefaultConfig.Width)
+	dir := o.flags.String("dir", ".", "output directory")
+	identity, err := o.parse(args)
+	if err != nil {
+		return err
+	}
+	c, err := o.configuration()
+	if err != nil {
+		return err
+	}
+	return jdenticon.WriteAppIcons(*dir, identity, c)
+}
+
+func runTerm(args []string) error {
+	o := newOptions("term", 16)
+	mode := o.flags.String("mode", "auto", "output mode: auto, truecolor, 256, ascii, sixel or kitty")
+	icon, err := o.icon(args)
+	if err != nil {
+		return err
+	}
+	var m jdenticon.TerminalMode
+	switch *mode {
+	case "auto":
+		m = jdenticon.TerminalModeFromEnv()
+	case "truecolor":
+		m = jdenticon.TrueColor
+	case "256":
+		m = jdenticon.Color256
+	case "ascii":
+		m = jdenticon.ASCII
+	case "sixel":
+		return o.write(icon.Sixel)
+	case "kitty":
+		return o.write(icon.Kitty)
+	default:
+		return fmt.Errorf("term: unknown mode %q", *mode)
+	}
+	return o.write(func(w io.Writer) error {
+		return icon.Terminal(w, o.size, m)
+	})
+}
+
+// runCollisions reads identities one per line from the file, - for stdin,
+// and reports those whose icons have the same features, then with -near the
+// pairs of features at least that similar.
+func runCollisions(args []string) error {
+	o := newOptions("collisions", jdenticon.DefaultConfig.Width)
+	min := o.flags.Float64("near", 0, fmt.Sprintf(
+		"also report pairs of icons at least this similar, e.g. %.1f; compares every pair of distinct icons, quadratic in their number",
+		jdenticon.DefaultNearSimilarity))
+	name, err := o.parse(args)
+	if err != nil {
+		return err
+	}
+	c, err := o.configuration()
+	if err != nil {
+		return err
+	}
+	identities, err := readLines(name)
+	if err != nil {
+		return err
+	}
+
+	groups := map[string][]string{}
+	features := []*jdenticon.Features{}
+	for _, identity := range identities {
+		f := jdenticon.Fingerprint(identity, c)
+		key := f.String()
+		if _, ok := groups[key]; !ok {
+			features = append(features, f)
+		}
+		groups[key] = append(groups[key], identity)
+	}
+
+	return o.write(func(w io.Writer) error {
+		colliding := 0
+		for _, f := range features {
+			if group := groups[f.String()]; len(group) > 1 {
+				colliding += len(group)
+				if _, err := fmt.Fprintf(w, "collision\t%s\t%s\n", f, strings.Join(group, ", ")); err != nil {
+					return err
+				}
+			}
+		}
+		if *min <= 0 {
+			_, err := fmt.Fprintf(w, "%d identities, %d distinct icons, %d in collisions\n",
+				len(identities), len(features), colliding)
+			return err
+		}
+		near := 0
+		for i, a := range features {
+			for _, b := range features[i+1:] {
+				s := jdenticon.Similarity(a, b)
+				if s < *min {
+					continue
+				}
+				near++
+				_, err := fmt.Fprintf(w, "near\t%.2f\t%s\t%s\n", s,
+					strings.Join(groups[a.String()], ", "), strings.Join(groups[b.String()], ", "))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err := fmt.Fprintf(w, "%d identities, %d distinct icons, %d in collisions, %d near collisions\n",
+			len(identities), len(features), colliding, near)
+		return err
+	})
+}
+
+// readLines returns the non-empty lines of the file, - for stdin.
+func readLines(name string) ([]string, error) {
+	r := io.Reader(os.Stdin)
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close() // nolint:errcheck
+		r = f
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -9,26 +9,54 @@ import (
 
 func (j *jdenticon) hue() float64 {
 	var hue float64
-	if j.config.Hues == -1 {
+	if j.config.Hues != -1 {
+		hue = float64(j.config.Hues) / 360
+	} else if j.uniform() {
+		hue = float64(j.uniformHue()) / 0xfffffff
+	} else {
 		h, _ := strconv.ParseInt("0x"+j.hash[len(j.hash)-7:], 0, 64)
 		hue = float64(h) / 0xfffffff
-	} else {
-		hue = float64(j.config.Hues) / 360
 	}
 	return hue
 }
 
 func (j *jdenticon) theme() []string {
+	if len(j.config.Palette) > 0 {
+		return j.paletteTheme()
+	}
 	hue := j.hue()
-	darkgray := j.config.Grayscale.color(hue, 0)
-	midcolor := j.config.Colored.color(hue, 0.5)
-	lightgray := j.config.Grayscale.color(hue, 1)
-	lightcolor := j.config.Colored.color(hue, 1)
-	darkcolor := j.config.Colored.color(hue, 0)
+	if j.config.WideGamut {
+		return j.wideTheme(hue)
+	}
+	v := j.config.Variant
+	darkgray := j.config.Grayscale.color(hue, 0, v)
+	midcolor := j.config.Colored.color(hue, 0.5, v)
+	lightgray := j.config.Grayscale.color(hue, 1, v)
+	lightcolor := j.config.Colored.color(hue, 1, v)
+	darkcolor := j.config.Colored.color(hue, 0, v)
 	return []string{darkgray, midcolor, lightgray, lightcolor, darkcolor}
 }
 
+// wideTheme is the theme with the HSL colors taken as Display P3 coordinates.
+func (j *jdenticon) wideTheme(hue float64) []string {
+	color := func(c Color, p float64) string {
+		l := j.config.Variant.lightness(c.lightness(p))
+		return j.svg.wideColor(correctedHslColor(hue, c.Saturation, l))
+	}
+	return []string{
+		color(j.config.Grayscale, 0),
+		color(j.config.Colored, 0.5),
+		color(j.config.Grayscale, 1),
+		color(j.config.Colored, 1),
+		color(j.config.Colored, 0),
+	}
+}
+
 func correctedHsl(h, s, l float64) string {
+	return correctedHslColor(h, s, l).Hex()
+}
+
+func correctedHslColor(h, s, l float64) colorful.Color {
 	correctors := []float64{0.55, 0.5, 0.5, 0.46, 0.6, 0.55, 0.55}
 	corrector := correctors[int(h*6+0.5)]
 	// Adjust the input lightness relative to the corrector
@@ -37,21 +65,36 @@ func correctedHsl(h, s, l float64) string {
 	} else {
 		l = corrector + (l-0.5)*(1-corrector)*2
 	}
-	return colorful.Hsl(h*360, s, l).Hex()
+	return colorful.Hsl(h*360, s, l)
 }
 
 func (j *jdenticon) colors() []string {
 	theme := j.theme()
 	available := []string{}
+	for _, idx := range j.colorIndexes(len(theme)) {
+		available = append(available, theme[idx])
+	}
+	if j.config.CVDSafe > 0 {
+		return cvdSafe(available, theme, j.config.CVDSafe)
+	}
+	return available
+}
+
+// colorIndexes returns the indexes in the theme of the three layer colors.
+func (j *jdenticon) colorIndexes(themeSize int) []int {
+	indexes := []int{}
 	var (
 		dark  bool
 		light bool
 	)
 	for i := 0; i < 3; i++ {
-		s := j.hash
-		s = "0x" + s[i+8:i+9]
-		h, _ := strconv.ParseInt(s, 0, 64)
-		idx := int(h) % len(theme)
+		var idx int
+		if j.uniform() {
+			idx = j.uniformColor(i, themeSize)
+		} else {
+			h, _ := strconv.ParseInt("0x"+j.hash[i+8:i+9], 0, 64)
+			idx = int(h) % themeSize
+		}
 		if idx == 0 || idx == 4 {
 			if dark {
 				idx = 1
@@ -64,9 +107,9 @@ func (j *jdenticon) colors() []string {
 			}
 			light = true
 		}
-		available = append(available, theme[idx])
+		indexes = append(indexes, idx)
 	}
-	return available
+	return indexes
 }
 
 func toHex(c color.Color) string {
@@ -31,6 +31,63 @@ type Config struct {
 	Width      int
 	Height     int
 	Padding    float64
+	// Style of the icon, geometric shapes or blocks.
+	Style Style
+	// Blocks is the number of blocks along each edge in StyleBlocks, 0 for
+	// DefaultBlocks.
+	Blocks int
+	// Fit selects how non-square icons are filled.
+	Fit Fit
+	// Layout of the grid, nil for ClassicLayout. Layouts failing
+	// Layout.Validate fall back to ClassicLayout.
+	Layout *Layout
+	// Symmetry of the cells of a slot.
+	Symmetry Symmetry
+	// Inner and Outer replace the built-in shape sets when not nil.
+	Inner ShapeSet
+	Outer ShapeSet
+	// FontFamily and FontWeight of Initials, empty and 0 for
+	// DefaultFontFamily and DefaultFontWeight.
+	FontFamily string
+	FontWeight int
+	// Mask clips the icon to the shape of an avatar when not nil. The
+	// padding applies within the visible area.
+	Mask Mask
+	// Stroke outlines every shape layer when not nil, in the dark gray of the
+	// theme by default.
+	Stroke *Border
+	// Ring draws a border around the icon, or along the mask, when not nil,
+	// in the main color of the theme by default.
+	Ring *Border
+	// Shadow casts a soft shadow below the shape layers when not nil.
+	Shadow *Shadow
+	// Gradient and BackgroundGradient shade the shape layers and the
+	// background when not nil.
+	Gradient           *Gradient
+	BackgroundGradient *Gradient
+	// BackgroundTint replaces Background with a color derived from the hue
+	// of the identity when not nil.
+	BackgroundTint *Tint
+	// Palette replaces the colors derived from the hue when not empty. With
+	// VariantDark the lightness of the palette colors is inverted.
+	Palette Palette
+	// CVDSafe is the least CIEDE2000 difference between layer colors in
+	// normal vision and with protanopia, deuteranopia and tritanopia, 0 to
+	// keep the colors as picked. DefaultCVDDeltaE suits most uses.
+	CVDSafe float64
+	// WideGamut takes the theme colors as Display P3 coordinates, for more
+	// saturated colors on wide gamut displays. SVG images keep sRGB fallbacks
+	// and PNG images embed the Display P3 profile.
+	WideGamut bool
+	// Variant adapts the colors and the background to light or dark
+	// surfaces.
+	Variant Variant
+	// Salt rerolls the icon of an identity: every salt gives another stable
+	// icon, 0 the original one. See FindDistinct.
+	Salt int
+	// Algorithm maps the hash to the features, AlgorithmClassic by default.
+	// AlgorithmUniform gives other icons for the same identities.
+	Algorithm Algorithm
 }
 
 type Color struct {
@@ -51,8 +108,8 @@ func (c Color) lightness(p float64) float64 {
 	return c.Lightness[0] + c.Lightness[0]*p
 }
 
-func (c Color) color(hue float64, lightness float64) string {
-	return correctedHsl(hue, c.Saturation, c.lightness(lightness))
+func (c Color) color(hue float64, lightness float64, v Variant) string {
+	return correctedHsl(hue, c.Saturation, v.lightness(c.lightness(lightness)))
 }
 
 /*
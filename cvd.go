@@ -0,0 +1,230 @@
+package jdenticon
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// Deficiency is a color vision deficiency.
+type Deficiency int
+
+const (
+	// Protanopia lacks the long wavelength (red) cones.
+	Protanopia Deficiency = iota + 1
+	// Deuteranopia lacks the medium wavelength (green) cones.
+	Deuteranopia
+	// Tritanopia lacks the short wavelength (blue) cones.
+	Tritanopia
+)
+
+// DefaultCVDDeltaE is a CIEDE2000 difference that keeps layer colors apart
+// at a glance, for Config.CVDSafe.
+const DefaultCVDDeltaE = 12
+
+// Full severity simulation matrices in linear RGB, from Machado, Oliveira and
+// Fernandes, "A Physiologically-based Model for Simulation of Color Vision
+// Deficiency", 2009.
+// nolint:gochecknoglobals
+var cvdMatrices = map[Deficiency][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// simulate returns the color as seen with the deficiency.
+func (d Deficiency) simulate(c colorful.Color) colorful.Color {
+	m, ok := cvdMatrices[d]
+	if !ok {
+		return c
+	}
+	return convertLinear(c, m).Clamped()
+}
+
+// simulateP3 returns the Display P3 color as seen with the deficiency, in
+// Display P3. The matrices apply to linear sRGB, which is only clipped in P3.
+func (d Deficiency) simulateP3(c colorful.Color) colorful.Color {
+	m, ok := cvdMatrices[d]
+	if !ok {
+		return c
+	}
+	srgb := convertLinear(convertLinear(c, p3ToSRGBMatrix), m)
+	return convertLinear(srgb, srgbToP3Matrix).Clamped()
+}
+
+func (d Deficiency) simulateHex(hex string) string {
+	c, err := colorful.Hex(hex)
+	if err != nil {
+		return hex
+	}
+	return d.simulate(c).Hex()
+}
+
+// SimulateImage returns the image as seen with the deficiency.
+func SimulateImage(img image.Image, d Deficiency) *image.NRGBA {
+	b := img.Bounds()
+	result := image.NewNRGBA(b)
+	cache := map[color.NRGBA]color.NRGBA{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			s, ok := cache[c]
+			if !ok {
+				sim := d.simulate(colorful.Color{
+					R: float64(c.R) / 255,
+					G: float64(c.G) / 255,
+					B: float64(c.B) / 255,
+				})
+				s.R, s.G, s.B = sim.RGB255()
+				s.A = c.A
+				cache[c] = s
+			}
+			result.SetNRGBA(x, y, s)
+		}
+	}
+	return result
+}
+
+// Simulate returns the icon with its colors as seen with the deficiency, in
+// every output format.
+func (j *jdenticon) Simulate(d Deficiency) Jdenticon {
+	return j.simulate(d)
+}
+
+func (j *jdenticon) simulate(d Deficiency) *jdenticon {
+	r := *j
+	r.simulated = d
+	svg := *j.svg
+	// the P3 colors of the theme are simulated in P3 and registered again
+	// under their new fallbacks
+	svg.p3 = nil
+	simulateHex := func(hex string) string {
+		if p3, ok := j.svg.p3[hex]; ok {
+			return svg.wideColor(d.simulateP3(p3))
+		}
+		return d.simulateHex(hex)
+	}
+	mapPaths := func(paths Paths) Paths {
+		result := make(Paths, len(paths))
+		for i, p := range paths {
+			if p.Fill != "none" {
+				p.Fill = simulateHex(p.Fill)
+			}
+			if p.Stroke != "" {
+				p.Stroke = simulateHex(p.Stroke)
+			}
+			result[i] = p
+		}
+		return result
+	}
+	svg.Paths = mapPaths(j.svg.Paths)
+	svg.Border = mapPaths(j.svg.Border)
+	svg.Texts = make([]Text, len(j.svg.Texts))
+	for i, t := range j.svg.Texts {
+		t.Fill = simulateHex(t.Fill)
+		t.outline.Fill = simulateHex(t.outline.Fill)
+		svg.Texts[i] = t
+	}
+	svg.Filters = make([]Filter, len(j.svg.Filters))
+	for i, f := range j.svg.Filters {
+		f.Color = simulateHex(f.Color)
+		svg.Filters[i] = f
+	}
+	svg.Gradients = make([]GradientFill, len(j.svg.Gradients))
+	for i, g := range j.svg.Gradients {
+		g.From = simulateHex(g.From)
+		g.To = simulateHex(g.To)
+		svg.Gradients[i] = g
+	}
+	r.svg = &svg
+	return &r
+}
+
+// cvdDistance returns the smallest CIEDE2000 difference of the colors in
+// normal vision and with each deficiency.
+func cvdDistance(a, b colorful.Color) float64 {
+	result := a.DistanceCIEDE2000(b)
+	for _, d := range []Deficiency{Protanopia, Deuteranopia, Tritanopia} {
+		result = math.Min(result, d.simulate(a).DistanceCIEDE2000(d.simulate(b)))
+	}
+	// colorful scales the lightness to 1 instead of 100
+	return result * 100
+}
+
+// cvdSafe replaces layer colors too close to an earlier one for some viewers,
+// with another color of the theme or, when none fits, with a lighter or
+// darker variant. Colors repeated on purpose by colors() stay the same.
+func cvdSafe(colors, theme []string, min float64) []string {
+	result := append([]string{}, colors...)
+	fits := func(i int, candidate string) bool {
+		c, err := colorful.Hex(candidate)
+		if err != nil {
+			return true
+		}
+		for _, other := range result[:i] {
+			if other == candidate {
+				continue
+			}
+			o, err := colorful.Hex(other)
+			if err == nil && cvdDistance(c, o) < min {
+				return false
+			}
+		}
+		return true
+	}
+	used := func(i int, candidate string) bool {
+		for _, other := range result[:i] {
+			if other == candidate {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 1; i < len(result); i++ {
+		if fits(i, result[i]) {
+			continue
+		}
+		replaced := false
+		for _, alt := range theme {
+			if !used(i, alt) && fits(i, alt) {
+				result[i], replaced = alt, true
+				break
+			}
+		}
+		if replaced {
+			continue
+		}
+		c, err := colorful.Hex(result[i])
+		if err != nil {
+			continue
+		}
+		h, s, l := c.Hsl()
+		for step := 1; step <= 20 && !replaced; step++ {
+			for _, sign := range []float64{1, -1} {
+				v := l + sign*float64(step)*0.04
+				if v < 0 || v > 1 {
+					continue
+				}
+				if candidate := colorful.Hsl(h, s, v).Hex(); fits(i, candidate) {
+					result[i], replaced = candidate, true
+					break
+				}
+			}
+		}
+	}
+	return result
+}
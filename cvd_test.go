@@ -0,0 +1,83 @@
+package jdenticon
+
+import (
+	"math"
+	"testing"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// pairs from Sharma, Wu and Dalal, "The CIEDE2000 Color-Difference Formula:
+// Implementation Notes, Supplementary Test Data, and Mathematical
+// Observations", 2005
+func TestCIEDE2000(t *testing.T) {
+	tests := []struct {
+		a, b [3]float64
+		want float64
+	}{
+		{[3]float64{50, 2.6772, -79.7751}, [3]float64{50, 0, -82.7485}, 2.0425},
+		{[3]float64{50, 3.1571, -77.2803}, [3]float64{50, 0, -82.7485}, 2.8615},
+		{[3]float64{50, 0, 0}, [3]float64{50, -1, 2}, 2.3669},
+		{[3]float64{50, 2.5, 0}, [3]float64{73, 25, -18}, 27.1492},
+		{[3]float64{50, 2.5, 0}, [3]float64{50, 3.1736, 0.5854}, 1.0000},
+		{[3]float64{60.2574, -34.0099, 36.2677}, [3]float64{60.4626, -34.1751, 39.4387}, 1.2644},
+		{[3]float64{22.7233, 20.0904, -46.694}, [3]float64{23.0331, 14.973, -42.5619}, 2.0373},
+		{[3]float64{2.0776, 0.0795, -1.135}, [3]float64{0.9033, -0.0636, -0.5514}, 0.9082},
+	}
+	lab := func(v [3]float64) colorful.Color {
+		// colorful scales the lightness to 1 instead of 100
+		return colorful.Lab(v[0]/100, v[1]/100, v[2]/100)
+	}
+	for _, tt := range tests {
+		if got := lab(tt.a).DistanceCIEDE2000(lab(tt.b)) * 100; math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("CIEDE2000(%v, %v) = %.4f, want %.4f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSimulateRed(t *testing.T) {
+	tests := []struct {
+		d    Deficiency
+		want string
+	}{
+		{Protanopia, "#6d5f00"},
+		{Deuteranopia, "#a39000"},
+		{Tritanopia, "#ff000f"},
+		{0, "#ff0000"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.simulateHex("#ff0000"); got != tt.want {
+			t.Errorf("%d: simulateHex(#ff0000) = %s, want %s", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestSimulateWideGamut(t *testing.T) {
+	c := *DefaultConfig
+	c.WideGamut = true
+	j := newJdenticon(c.hashIdentity("wide"), &c)
+	s := j.simulate(Deuteranopia)
+	if len(s.svg.p3) == 0 {
+		t.Fatal("simulated icon has no P3 colors")
+	}
+	for i, p := range s.svg.Paths {
+		original, ok := j.svg.p3[j.svg.Paths[i].Fill]
+		if !ok {
+			continue
+		}
+		p3, ok := s.svg.p3[p.Fill]
+		if !ok {
+			t.Errorf("path %d: fill %s has no P3 color", i, p.Fill)
+			continue
+		}
+		if want := Deuteranopia.simulateP3(original); p3 != want {
+			t.Errorf("path %d: P3 color %v, want %v", i, p3, want)
+		}
+	}
+	// no P3 color of the original icon is left over
+	for hex, p3 := range s.svg.p3 {
+		if other, ok := j.svg.p3[hex]; ok && other == p3 {
+			t.Errorf("P3 color %v of %s is not simulated", p3, hex)
+		}
+	}
+}
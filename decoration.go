@@ -0,0 +1,103 @@
+package jdenticon
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Border is a line along the shapes or around the icon.
+type Border struct {
+	// Width of the line relative to the smaller side of the icon.
+	Width float64
+	// Color of the line, nil for a color of the theme.
+	Color color.Color
+}
+
+// Shadow is a soft shadow cast by the shape layers.
+type Shadow struct {
+	// OffsetX and OffsetY move the shadow, relative to the smaller side of
+	// the icon.
+	OffsetX float64
+	OffsetY float64
+	// Blur is the standard deviation of the blur relative to the smaller side
+	// of the icon.
+	Blur float64
+	// Color of the shadow, nil for translucent black. The alpha channel sets
+	// the opacity.
+	Color color.Color
+}
+
+// default opacity of shadows without a color
+const shadowOpacity = 0.35
+
+// Filter is a drop shadow referenced by the paths casting it.
+type Filter struct {
+	ID      string
+	DX      float64
+	DY      float64
+	Blur    float64
+	Color   string
+	Opacity float64
+}
+
+// decorate adds the gradients, the stroke and the shadow of the config to
+// the layers starting at the path index first, the background gradient to
+// the paths before, and the ring around the icon.
+func (j *jdenticon) decorate(first int) {
+	c := j.config
+	side := math.Min(j.geometry.X, j.geometry.Y)
+	theme := j.theme()
+	for i := range j.svg.Paths {
+		if i < first {
+			j.shade(i, c.BackgroundGradient)
+		} else {
+			j.shade(i, c.Gradient)
+		}
+	}
+	if c.Shadow != nil {
+		f := Filter{
+			DX:      round2(c.Shadow.OffsetX * side),
+			DY:      round2(c.Shadow.OffsetY * side),
+			Blur:    round2(c.Shadow.Blur * side),
+			Color:   "#000000",
+			Opacity: shadowOpacity,
+		}
+		if c.Shadow.Color != nil {
+			f.Color = toHex(c.Shadow.Color)
+			f.Opacity = opacity(c.Shadow.Color)
+		}
+		f.ID = "jdenticon-shadow-" + hashIdentity(fmt.Sprintf("%s %v", j.hash, f))[:8]
+		j.svg.Filters = append(j.svg.Filters, f)
+		for i := first; i < len(j.svg.Paths); i++ {
+			j.svg.Paths[i].Filter = f.ID
+		}
+	}
+	if c.Stroke != nil {
+		stroke := c.Stroke.color(theme[0])
+		for i := first; i < len(j.svg.Paths); i++ {
+			j.svg.Paths[i].Stroke = stroke
+			j.svg.Paths[i].StrokeWidth = round2(c.Stroke.Width * side)
+		}
+	}
+	if c.Ring != nil {
+		outline := Shapes{&Polygon{[]Point{{0, 0}, {j.geometry.X, 0}, {j.geometry.X, j.geometry.Y}, {0, j.geometry.Y}}, false}}
+		if c.Mask != nil {
+			outline = c.Mask.Outline(j.geometry.X, j.geometry.Y)
+		}
+		// the outer half of the line lies outside the icon or the mask
+		j.svg.Border = append(j.svg.Border, Path{
+			Fill:        "none",
+			Stroke:      c.Ring.color(theme[1]),
+			StrokeWidth: round2(2 * c.Ring.Width * side),
+			Shapes:      outline,
+		})
+	}
+}
+
+func (b *Border) color(theme string) string {
+	if b.Color == nil {
+		return theme
+	}
+	return toHex(b.Color)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo"
@@ -41,6 +42,8 @@ func icon(c echo.Context) error {
 	height := jdenticon.DefaultConfig.Height
 	padding := jdenticon.DefaultConfig.Padding
 	background := jdenticon.DefaultConfig.Background
+	variant := preferredVariant(c)
+	salt := 0
 	var config *jdenticon.Config
 
 	for name := range c.QueryParams() {
@@ -89,6 +92,14 @@ func icon(c echo.Context) error {
 			if val, err := colorful.Hex(c.QueryParam(name)); err == nil {
 				background = val
 			}
+		case "variant":
+			if val, err := jdenticon.ParseVariant(c.QueryParam(name)); err == nil {
+				variant = val
+			}
+		case "salt":
+			if val, err := strconv.Atoi(c.QueryParam(name)); err == nil {
+				salt = val
+			}
 		case "config":
 			if val, err := jdenticon.ConfigFromString(c.QueryParam(name)); err == nil {
 				config = val
@@ -111,6 +122,8 @@ func icon(c echo.Context) error {
 		config.Height = height
 		config.Padding = padding
 	}
+	config.Variant = variant
+	config.Salt = salt
 	icon := jdenticon.NewWithConfig(identity, config)
 	svg, err := icon.SVG()
 	if err != nil {
@@ -119,3 +132,18 @@ func icon(c echo.Context) error {
 	}
 	return c.Blob(http.StatusOK, "image/svg+xml", svg)
 }
+
+// preferredVariant returns the variant from the Sec-CH-Prefers-Color-Scheme
+// client hint, which browsers send once the server asked for it.
+func preferredVariant(c echo.Context) jdenticon.Variant {
+	const hint = "Sec-CH-Prefers-Color-Scheme"
+	c.Response().Header().Set("Accept-CH", hint)
+	c.Response().Header().Add("Vary", hint)
+	// the hint is a structured header string like "dark"
+	scheme := strings.Trim(c.Request().Header.Get(hint), `"`)
+	variant, err := jdenticon.ParseVariant(scheme)
+	if err != nil {
+		return jdenticon.VariantLight
+	}
+	return variant
+}
@@ -0,0 +1,192 @@
+package jdenticon
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FingerprintHues is the number of hue buckets of a fingerprint. Hues in the
+// same bucket are hard to tell apart in small icons.
+const FingerprintHues = 24
+
+// DefaultNearSimilarity is the Similarity from which icons are near
+// collisions: all but about one feature are the same.
+const DefaultNearSimilarity = 0.9
+
+// SlotFeatures are the features of a layout slot.
+type SlotFeatures struct {
+	Slot string `json:"slot"`
+	// Shape is the index of the shape in its shape set.
+	Shape int `json:"shape"`
+	// Rotation of the first cell in quarter turns, modulo the quarter turns
+	// after which the shape looks the same: always 0 for a circle.
+	Rotation int `json:"rotation"`
+	// Color is the index of the slot color in the theme: dark gray, mid
+	// color, light gray, light color and dark color.
+	Color int `json:"color"`
+}
+
+// Features are the decisive visual features of an identicon: icons with the
+// same features look the same, whatever their hashes.
+type Features struct {
+	// Hue is the hue bucket, from 0 to FingerprintHues-1.
+	Hue int `json:"hue"`
+	// Slots are the features of the layout slots, for StyleGeometric.
+	Slots []SlotFeatures `json:"slots,omitempty"`
+	// Blocks are the filled blocks of the left half row by row, for
+	// StyleBlocks.
+	Blocks []bool `json:"blocks,omitempty"`
+}
+
+// Fingerprint returns the features of the identicon of the identity. Colors
+// come from the hue or the palette before any Config.CVDSafe adjustment.
+func Fingerprint(identity string, c *Config) *Features {
+	j := &jdenticon{config: c, hash: c.hashIdentity(identity)}
+	f := &Features{Hue: int(j.hue()*FingerprintHues+0.5) % FingerprintHues}
+	if c.Style == StyleBlocks {
+		n := c.Blocks
+		if n <= 0 {
+			n = DefaultBlocks
+		}
+		f.Blocks = j.blockBits(n * ((n + 1) / 2))
+		return f
+	}
+	// every theme has five colors
+	colors := j.colorIndexes(5)
+	for _, slot := range c.layout().Slots {
+		set := c.outer()
+		if slot.Inner {
+			set = c.inner()
+		}
+		shape := j.shape(set, slot.Shape)
+		f.Slots = append(f.Slots, SlotFeatures{
+			Slot:     slot.Name,
+			Shape:    shape,
+			Rotation: j.rotation(slot.Rotation) % rotationPeriod(set[shape], slot.Shape),
+			Color:    colors[slot.Color],
+		})
+	}
+	return f
+}
+
+// rotationPeriods caches rotationPeriod by shape function and digit position.
+var rotationPeriods sync.Map // nolint:gochecknoglobals
+
+type rotationKey struct {
+	fn    uintptr
+	index int
+}
+
+// rotationPeriod returns the least number of quarter turns, 1, 2 or 4, after
+// which the rendered shape covers the same pixels.
+func rotationPeriod(fn ShapeFunc, index int) int {
+	key := rotationKey{reflect.ValueOf(fn).Pointer(), index}
+	if period, ok := rotationPeriods.Load(key); ok {
+		return period.(int)
+	}
+	// shapes may leave their cell, so the cell is drawn in the middle of a
+	// canvas three cells wide
+	const cell = 24
+	render := func(turns int) *image.Alpha {
+		center := &Point{X: 1.5 * cell, Y: 1.5 * cell}
+		shapes := fn(cell, index)
+		for _, shape := range shapes {
+			shape.Translate(cell, cell)
+			shape.Rotate(float64(turns)*90, center)
+		}
+		mask, err := rasterizePath(shapes.String(), 3*cell, 3*cell)
+		if err != nil {
+			return nil
+		}
+		return mask
+	}
+	period := 4
+	if original := render(0); original != nil {
+		for _, turns := range []int{1, 2} {
+			if rotated := render(turns); rotated != nil && sameCoverage(original, rotated) {
+				period = turns
+				break
+			}
+		}
+	}
+	rotationPeriods.Store(key, period)
+	return period
+}
+
+// sameCoverage reports whether the masks differ by less than 2% of their
+// coverage, which absorbs anti-aliasing differences of rotated edges.
+func sameCoverage(a, b *image.Alpha) bool {
+	var diff, total float64
+	for i := range a.Pix {
+		diff += math.Abs(float64(a.Pix[i]) - float64(b.Pix[i]))
+		total += float64(a.Pix[i])
+	}
+	return diff <= 0.02*total
+}
+
+// String returns the features in a compact form, equal for equal features.
+func (f *Features) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "h%d", f.Hue)
+	for _, s := range f.Slots {
+		fmt.Fprintf(&b, " %s:%d/%d/%d", s.Slot, s.Shape, s.Rotation, s.Color)
+	}
+	if len(f.Blocks) > 0 {
+		b.WriteString(" ")
+		for _, on := range f.Blocks {
+			if on {
+				b.WriteString("1")
+			} else {
+				b.WriteString("0")
+			}
+		}
+	}
+	return b.String()
+}
+
+// Similarity returns the share of equal features of two fingerprints, from 0
+// to 1 for the same features. Hues in neighboring buckets count half.
+// Fingerprints of different configs are not comparable.
+func Similarity(a, b *Features) float64 {
+	same, total := 0.0, 1.0
+	switch d := (a.Hue - b.Hue + FingerprintHues) % FingerprintHues; d {
+	case 0:
+		same++
+	case 1, FingerprintHues - 1:
+		same += 0.5
+	}
+	for i := 0; i < len(a.Slots) || i < len(b.Slots); i++ {
+		total += 3
+		if i >= len(a.Slots) || i >= len(b.Slots) {
+			continue
+		}
+		sa, sb := a.Slots[i], b.Slots[i]
+		if sa.Shape == sb.Shape {
+			same++
+		}
+		if sa.Rotation == sb.Rotation {
+			same++
+		}
+		if sa.Color == sb.Color {
+			same++
+		}
+	}
+	if len(a.Blocks) > 0 || len(b.Blocks) > 0 {
+		// the blocks weigh as much as the hue
+		total++
+		if len(a.Blocks) == len(b.Blocks) {
+			equal := 0
+			for i := range a.Blocks {
+				if a.Blocks[i] == b.Blocks[i] {
+					equal++
+				}
+			}
+			same += float64(equal) / float64(len(a.Blocks))
+		}
+	}
+	return same / total
+}
@@ -0,0 +1,42 @@
+package jdenticon
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	blocks := &Config{Style: StyleBlocks}
+	for _, c := range []*Config{DefaultConfig, blocks} {
+		features := make([]*Features, 200)
+		for i := range features {
+			features[i] = Fingerprint(strconv.Itoa(i), c)
+		}
+		for i, a := range features {
+			if s := Similarity(a, a); s != 1 {
+				t.Errorf("Similarity(a, a) = %f, want 1", s)
+			}
+			for _, b := range features[i+1:] {
+				ab, ba := Similarity(a, b), Similarity(b, a)
+				if ab != ba {
+					t.Errorf("Similarity(a, b) = %f, Similarity(b, a) = %f", ab, ba)
+				}
+				if ab < 0 || ab > 1 {
+					t.Errorf("Similarity(a, b) = %f, want from 0 to 1", ab)
+				}
+			}
+		}
+	}
+}
+
+func TestFingerprintRotation(t *testing.T) {
+	// the diamond and the circle look the same in every rotation
+	invariant := map[int]bool{2: true, 3: true}
+	for i := 0; i < 500; i++ {
+		for _, s := range Fingerprint(strconv.Itoa(i), DefaultConfig).Slots {
+			if s.Slot != "center" && invariant[s.Shape] && s.Rotation != 0 {
+				t.Fatalf("slot %s with shape %d has rotation %d", s.Slot, s.Shape, s.Rotation)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package jdenticon
+
+import (
+	"math"
+)
+
+// Fit selects how icons fill a non-square size.
+type Fit int
+
+const (
+	// FitSquare centers a square icon and leaves the rest empty.
+	FitSquare Fit = iota
+	// FitTile repeats the square icon along the longer axis, with one copy
+	// centered like FitSquare places it.
+	FitTile
+	// FitStretch stretches the grid to non-square cells.
+	FitStretch
+)
+
+// grid is the area covered by one copy of the layout.
+type grid struct {
+	origin Point
+	size   Point
+}
+
+// fit returns the grids covering the icon for the configured Fit.
+func (j *jdenticon) fit() []grid {
+	switch j.config.Fit {
+	case FitStretch:
+		pad := Point{X: j.geometry.X * j.config.padding(), Y: j.geometry.Y * j.config.padding()}
+		return []grid{{
+			origin: pad,
+			size:   Point{X: j.geometry.X - 2*pad.X, Y: j.geometry.Y - 2*pad.Y},
+		}}
+	case FitTile:
+		if j.geometry.X != j.geometry.Y {
+			return j.tiles()
+		}
+	}
+	width := j.geometry.X - j.paddings.X
+	return []grid{{origin: j.zero, size: Point{X: width, Y: width}}}
+}
+
+// tiles lays out square copies along the longer axis, starting from a
+// centered one and adding copies to both sides until the icon is covered.
+func (j *jdenticon) tiles() []grid {
+	side := math.Min(j.geometry.X, j.geometry.Y)
+	long := math.Max(j.geometry.X, j.geometry.Y)
+	pad := side * j.config.padding()
+	first := (long - side) / 2
+	n := int(math.Ceil(first / side))
+	grids := []grid{}
+	for k := -n; k <= n; k++ {
+		offset := first + float64(k)*side + pad
+		g := grid{size: Point{X: side - 2*pad, Y: side - 2*pad}}
+		if j.geometry.X > j.geometry.Y {
+			g.origin = Point{X: offset, Y: pad}
+		} else {
+			g.origin = Point{X: pad, Y: offset}
+		}
+		grids = append(grids, g)
+	}
+	return grids
+}
@@ -8,5 +8,5 @@ require (
 	github.com/labstack/gommon v0.3.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.0.2
 	github.com/valyala/fasttemplate v1.2.0 // indirect
-	golang.org/x/crypto v0.0.0-20200709230013-948cd5f35899 // indirect
+	golang.org/x/image v0.10.0
 )
@@ -0,0 +1,169 @@
+package jdenticon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// Sixel and Kitty show the rasterized icon in terminals supporting the
+// respective graphics protocol.
+
+// maximum number of color registers used in sixel images
+const sixelColors = 256
+
+// payload size of a single kitty graphics escape sequence
+const kittyChunk = 4096
+
+// Sixel writes the icon as a DEC sixel image. Transparent pixels keep the
+// terminal background.
+func (j *jdenticon) Sixel(w io.Writer) error {
+	img, err := j.rasterize()
+	if err != nil {
+		return err
+	}
+	return encodeSixel(w, img)
+}
+
+func encodeSixel(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	palette, indices := sixelPalette(img)
+
+	out := bufio.NewWriter(w)
+	// P2=1 leaves pixels without a sixel at the background color
+	fmt.Fprintf(out, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i, c := range palette {
+		fmt.Fprintf(out, "#%d;2;%d;%d;%d", i, c[0], c[1], c[2])
+	}
+	row := make([]byte, width)
+	for y := 0; y < height; y += 6 {
+		first := true
+		for c := range palette {
+			used := false
+			for x := 0; x < width; x++ {
+				var bits byte
+				for k := 0; k < 6 && y+k < height; k++ {
+					if indices[(y+k)*width+x] == c {
+						bits |= 1 << uint(k)
+					}
+				}
+				row[x] = 63 + bits
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+			if !first {
+				_ = out.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(out, "#%d", c)
+			writeSixelRow(out, row)
+		}
+		if y+6 < height {
+			_ = out.WriteByte('-')
+		}
+	}
+	_, _ = out.WriteString("\x1b\\")
+	return out.Flush()
+}
+
+// writeSixelRow writes the sixel characters of one band and color, run-length
+// encoding repeats and dropping trailing empty sixels.
+func writeSixelRow(out *bufio.Writer, row []byte) {
+	end := len(row)
+	for end > 0 && row[end-1] == 63 {
+		end--
+	}
+	for x := 0; x < end; {
+		n := 1
+		for x+n < end && row[x+n] == row[x] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, row[x])
+		} else {
+			for i := 0; i < n; i++ {
+				_ = out.WriteByte(row[x])
+			}
+		}
+		x += n
+	}
+}
+
+// sixelPalette collects the colors of the visible pixels in scan order and
+// returns the palette together with the palette index of every pixel, -1 for
+// transparent ones. Sixel colors are given in percent, and they are quantized
+// further until they fit the available registers.
+func sixelPalette(img image.Image) ([][3]int, []int) {
+	b := img.Bounds()
+	pixels := make([]color.NRGBA, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			pixels = append(pixels, color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA))
+		}
+	}
+	for step := 1; ; step *= 2 {
+		palette := [][3]int{}
+		registers := map[[3]int]int{}
+		indices := make([]int, len(pixels))
+		for i, p := range pixels {
+			if p.A < 0x80 {
+				indices[i] = -1
+				continue
+			}
+			key := [3]int{percent(p.R) / step * step, percent(p.G) / step * step, percent(p.B) / step * step}
+			idx, ok := registers[key]
+			if !ok {
+				idx = len(palette)
+				registers[key] = idx
+				palette = append(palette, key)
+			}
+			indices[i] = idx
+		}
+		if len(palette) <= sixelColors {
+			return palette, indices
+		}
+	}
+}
+
+func percent(v uint8) int {
+	return (int(v)*100 + 127) / 255
+}
+
+// Kitty writes the icon using the kitty terminal graphics protocol, sending
+// it as a PNG image split in chunks.
+func (j *jdenticon) Kitty(w io.Writer) error {
+	var buf bytes.Buffer
+	if err := j.PNG(&buf); err != nil {
+		return err
+	}
+	return encodeKitty(w, buf.Bytes())
+}
+
+func encodeKitty(w io.Writer, data []byte) error {
+	payload := base64.StdEncoding.EncodeToString(data)
+	out := bufio.NewWriter(w)
+	for first := true; first || len(payload) > 0; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunk {
+			chunk = chunk[:kittyChunk]
+		}
+		payload = payload[len(chunk):]
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(out, "\x1b_Ga=T,f=100,m=%d;%s\x1b\\", more, chunk)
+		} else {
+			fmt.Fprintf(out, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,139 @@
+package jdenticon
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEncodeSixel(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	green := color.NRGBA{G: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+
+	// two bands: red with a blue right column, then a blue row
+	twoColors := image.NewNRGBA(image.Rect(0, 0, 5, 7))
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 5; x++ {
+			if y == 6 || x == 4 {
+				twoColors.SetNRGBA(x, y, blue)
+			} else {
+				twoColors.SetNRGBA(x, y, red)
+			}
+		}
+	}
+	// a green pixel between transparent ones
+	transparent := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	transparent.SetNRGBA(1, 0, green)
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want string
+	}{
+		{
+			name: "two colors",
+			img:  twoColors,
+			want: "\x1bP0;1;0q\"1;1;5;7" +
+				"#0;2;100;0;0#1;2;0;0;100" +
+				"#0!4~$#1!4?~-" +
+				"#1!5@" +
+				"\x1b\\",
+		},
+		{
+			name: "transparent",
+			img:  transparent,
+			want: "\x1bP0;1;0q\"1;1;3;1" +
+				"#0;2;0;100;0" +
+				"#0?@" +
+				"\x1b\\",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeSixel(&buf, tt.img); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("encodeSixel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeKitty(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want string
+	}{
+		{
+			name: "empty",
+			size: 0,
+			want: "\x1b_Ga=T,f=100,m=0;\x1b\\",
+		},
+		{
+			// 3072 bytes are exactly 4096 base64 characters
+			name: "one chunk",
+			size: 3072,
+			want: "\x1b_Ga=T,f=100,m=0;" + strings.Repeat("A", kittyChunk) + "\x1b\\",
+		},
+		{
+			name: "two chunks",
+			size: 3073,
+			want: "\x1b_Ga=T,f=100,m=1;" + strings.Repeat("A", kittyChunk) + "\x1b\\" +
+				"\x1b_Gm=0;AA==\x1b\\",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeKitty(&buf, make([]byte, tt.size)); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("encodeKitty() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKitty(t *testing.T) {
+	c := *DefaultConfig
+	c.Width, c.Height = 300, 300
+	var buf bytes.Buffer
+	if err := NewWithConfig("kitty", &c).(Printer).Kitty(&buf); err != nil {
+		t.Fatal(err)
+	}
+	chunks := regexp.MustCompile("\x1b_G(?:a=T,f=100,)?m=([01]);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(buf.String(), -1)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want a PNG split in several", len(chunks))
+	}
+	var payload strings.Builder
+	for i, chunk := range chunks {
+		if more := i < len(chunks)-1; (chunk[1] == "1") != more {
+			t.Errorf("chunk %d has m=%s", i, chunk[1])
+		}
+		if len(chunk[2]) > kittyChunk {
+			t.Errorf("chunk %d has %d bytes", i, len(chunk[2]))
+		}
+		payload.WriteString(chunk[2])
+	}
+	data, err := base64.StdEncoding.DecodeString(payload.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 300 {
+		t.Errorf("image is %v, want 300x300", b)
+	}
+}
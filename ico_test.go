@@ -0,0 +1,71 @@
+package jdenticon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"testing"
+)
+
+func TestICO(t *testing.T) {
+	sizes := []int{16, 48, 256}
+	var buf bytes.Buffer
+	if err := New("favicon").(Encoder).ICO(&buf, sizes...); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	var dir struct {
+		Reserved, Type, Count uint16
+	}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &dir); err != nil {
+		t.Fatal(err)
+	}
+	if dir.Reserved != 0 || dir.Type != 1 || int(dir.Count) != len(sizes) {
+		t.Fatalf("ICONDIR = %+v, want 0, 1, %d", dir, len(sizes))
+	}
+
+	type entry struct {
+		Width, Height, Colors, Reserved uint8
+		Planes, BitCount                uint16
+		Size, Offset                    uint32
+	}
+	entries := make([]entry, len(sizes))
+	if err := binary.Read(bytes.NewReader(data[6:]), binary.LittleEndian, entries); err != nil {
+		t.Fatal(err)
+	}
+	offset := 6 + 16*len(sizes)
+	for i, e := range entries {
+		dim := uint8(sizes[i])
+		if sizes[i] == 256 {
+			dim = 0
+		}
+		if e.Width != dim || e.Height != dim || e.Colors != 0 || e.Reserved != 0 || e.Planes != 1 || e.BitCount != 32 {
+			t.Errorf("entry %d = %+v, want %d pixels in 32 bits", i, e, sizes[i])
+		}
+		if int(e.Offset) != offset {
+			t.Errorf("entry %d at offset %d, want %d", i, e.Offset, offset)
+		}
+		end := int(e.Offset) + int(e.Size)
+		if end > len(data) {
+			t.Fatalf("entry %d ends at %d after the file of %d bytes", i, end, len(data))
+		}
+		img, err := png.Decode(bytes.NewReader(data[e.Offset:end]))
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		if b := img.Bounds(); b.Dx() != sizes[i] || b.Dy() != sizes[i] {
+			t.Errorf("entry %d is %v, want %d pixels", i, b, sizes[i])
+		}
+		offset = end
+	}
+	if offset != len(data) {
+		t.Errorf("images end at %d, file has %d bytes", offset, len(data))
+	}
+
+	for _, size := range []int{0, 257} {
+		if err := New("favicon").(Encoder).ICO(&buf, size); err == nil {
+			t.Errorf("ICO(%d) succeeded", size)
+		}
+	}
+}
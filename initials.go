@@ -0,0 +1,365 @@
+package jdenticon
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultFontFamily is the font family of initials in SVG images. The Go font
+// comes first, because it is bundled to draw the other formats.
+const DefaultFontFamily = "Go, Helvetica, Arial, sans-serif"
+
+// DefaultFontWeight is the font weight of initials. Weights from 600 up are
+// drawn with the bundled bold font, lighter ones with the regular font.
+const DefaultFontWeight = 600
+
+const (
+	// font size relative to the side of the icon without paddings
+	initialsFontScale = 0.45
+	// widest the initials may be relative to the side of the icon
+	initialsMaxWidth = 0.8
+)
+
+// Initials creates an avatar showing the initials of the name on the main
+// color of its identicon, so both look alike for the same identity. The
+// initials are the first characters, as user-perceived grapheme clusters, of
+// the first and the last word of the name.
+func Initials(name string, c *Config) Jdenticon {
+	return newInitials(c.hashIdentity(name), initialsOf(name), c)
+}
+
+func newInitials(hash, initials string, c *Config) *jdenticon {
+	w := float64(c.Width)
+	h := float64(c.Height)
+	j := &jdenticon{
+		config: c,
+		svg: &SVG{
+			Width:  c.Width,
+			Height: c.Height,
+		},
+		hash:     hash,
+		initials: &initials,
+	}
+	j.geometry = Point{X: w, Y: h}
+	j.svg.clip(c, hash)
+	theme := j.theme()
+	background := theme[1]
+	j.svg.Paths = append(j.svg.Paths, Path{
+		Fill: background,
+		Shapes: Shapes{
+			&Polygon{[]Point{{0, 0}, {w, 0}, {w, h}, {0, h}}, false},
+		},
+	})
+	// the initials get no stroke and shadow, only the ring
+	j.decorate(len(j.svg.Paths))
+	if initials == "" {
+		return j
+	}
+
+	family := c.FontFamily
+	if family == "" {
+		family = DefaultFontFamily
+	}
+	weight := c.FontWeight
+	if weight == 0 {
+		weight = DefaultFontWeight
+	}
+	side := math.Min(w, h) * (1 - 2*c.padding())
+	text := Text{
+		X:          round2(w / 2),
+		Content:    initials,
+		FontFamily: family,
+		FontWeight: weight,
+		FontSize:   side * initialsFontScale,
+		Fill:       textColor(background, theme[0]),
+	}
+
+	f, err := bundledFont(weight)
+	if err != nil {
+		// the bundled fonts are known to parse, the SVG text is still fine
+		text.FontSize = round2(text.FontSize)
+		text.Y = round2(h/2 + text.FontSize*0.35)
+		j.svg.Texts = append(j.svg.Texts, text)
+		return j
+	}
+	var b sfnt.Buffer
+	if width := textWidth(f, &b, initials, text.FontSize); width > side*initialsMaxWidth {
+		text.FontSize *= side * initialsMaxWidth / width
+	}
+	text.FontSize = round2(text.FontSize)
+	capHeight := text.FontSize * 0.7
+	if m, err := f.Metrics(&b, toFixed(text.FontSize), font.HintingNone); err == nil && m.CapHeight > 0 {
+		capHeight = fromFixed(m.CapHeight)
+	}
+	text.Y = round2(h/2 + capHeight/2)
+	text.outline = Path{
+		Fill:   text.Fill,
+		Shapes: Shapes{textOutline(f, &b, initials, text.FontSize, text.X, text.Y)},
+	}
+	j.svg.Texts = append(j.svg.Texts, text)
+	return j
+}
+
+// textColor returns white, or the dark color when white would not stand out
+// on the background.
+func textColor(background, dark string) string {
+	c, err := colorful.Hex(background)
+	if err != nil {
+		return "#ffffff"
+	}
+	// contrast ratio of white on the background as defined by WCAG
+	if 1.05/(relativeLuminance(c)+0.05) < 3 {
+		return dark
+	}
+	return "#ffffff"
+}
+
+func relativeLuminance(c colorful.Color) float64 {
+	r, g, b := c.LinearRgb()
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+// initialsOf returns the initials of the first and the last word of the name.
+func initialsOf(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	result := initial(words[0])
+	if len(words) > 1 {
+		result += initial(words[len(words)-1])
+	}
+	return result
+}
+
+// initial returns the first grapheme cluster of the word with its first rune
+// in upper case.
+func initial(word string) string {
+	g := graphemes(word)
+	if len(g) == 0 {
+		return ""
+	}
+	runes := []rune(g[0])
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// graphemes splits s into user-perceived characters. It covers the cases that
+// matter for names and emoji: combining marks, variation selectors, emoji
+// modifiers and tags, zero width joiner sequences, flags made of regional
+// indicators and Hangul syllables made of jamo.
+func graphemes(s string) []string {
+	var (
+		result []string
+		start  int
+		prev   rune
+	)
+	count := 0 // runes in the current cluster
+	for i, r := range s {
+		if count > 0 && !extendsCluster(prev, r, s[start:i]) {
+			result = append(result, s[start:i])
+			start = i
+			count = 0
+		}
+		prev = r
+		count++
+	}
+	if count > 0 {
+		result = append(result, s[start:])
+	}
+	return result
+}
+
+const (
+	zeroWidthJoiner = 0x200d
+	regionalFirst   = 0x1f1e6
+	regionalLast    = 0x1f1ff
+)
+
+func extendsCluster(prev, r rune, cluster string) bool {
+	switch {
+	case prev == '\r' && r == '\n':
+		return true
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r == zeroWidthJoiner:
+		return true
+	case prev == zeroWidthJoiner:
+		// joiners only glue emoji together
+		return isPictographic(r)
+	case r >= 0xfe00 && r <= 0xfe0f, r >= 0xe0100 && r <= 0xe01ef:
+		// variation selectors
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff:
+		// emoji skin tone modifiers
+		return true
+	case r >= 0xe0020 && r <= 0xe007f:
+		// tags of subdivision flags
+		return true
+	case isRegional(prev) && isRegional(r):
+		// flags are pairs of regional indicators
+		return len([]rune(cluster))%2 == 1
+	}
+	return hangulJoins(prev, r)
+}
+
+// isPictographic reports whether r is in the main emoji blocks, the part of
+// Extended_Pictographic that joiner sequences use.
+func isPictographic(r rune) bool {
+	return r >= 0x1f000 && r <= 0x1faff ||
+		r >= 0x2300 && r <= 0x23ff ||
+		r >= 0x2600 && r <= 0x27bf ||
+		r >= 0x2b00 && r <= 0x2bff
+}
+
+func isRegional(r rune) bool {
+	return r >= regionalFirst && r <= regionalLast
+}
+
+// hangulJoins reports whether the jamo or syllables prev and r belong to the
+// same Hangul syllable.
+func hangulJoins(prev, r rune) bool {
+	const (
+		leadFirst, leadLast         = 0x1100, 0x115f
+		vowelFirst, vowelLast       = 0x1160, 0x11a7
+		trailFirst, trailLast       = 0x11a8, 0x11ff
+		syllableFirst, syllableLast = 0xac00, 0xd7a3
+	)
+	lead := func(r rune) bool { return r >= leadFirst && r <= leadLast }
+	vowel := func(r rune) bool { return r >= vowelFirst && r <= vowelLast }
+	trail := func(r rune) bool { return r >= trailFirst && r <= trailLast }
+	syllable := func(r rune) bool { return r >= syllableFirst && r <= syllableLast }
+	// syllables without a trailing consonant may still get one
+	open := func(r rune) bool { return syllable(r) && (r-syllableFirst)%28 == 0 }
+	switch {
+	case lead(prev):
+		return lead(r) || vowel(r) || syllable(r)
+	case vowel(prev) || open(prev):
+		return vowel(r) || trail(r)
+	case trail(prev) || syllable(prev):
+		return trail(r)
+	}
+	return false
+}
+
+// nolint:gochecknoglobals
+var (
+	fontsOnce sync.Once
+	fonts     [2]*sfnt.Font
+	fontsErr  error
+)
+
+// bundledFont returns the Go font matching the weight.
+func bundledFont(weight int) (*sfnt.Font, error) {
+	fontsOnce.Do(func() {
+		for i, data := range [][]byte{goregular.TTF, gobold.TTF} {
+			if fonts[i], fontsErr = sfnt.Parse(data); fontsErr != nil {
+				return
+			}
+		}
+	})
+	if fontsErr != nil {
+		return nil, fontsErr
+	}
+	if weight >= 600 {
+		return fonts[1], nil
+	}
+	return fonts[0], nil
+}
+
+// textWidth returns the advance of the text in the font at size.
+func textWidth(f *sfnt.Font, b *sfnt.Buffer, text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		idx, err := f.GlyphIndex(b, r)
+		if err != nil || idx == 0 {
+			continue
+		}
+		advance, err := f.GlyphAdvance(b, idx, toFixed(size), font.HintingNone)
+		if err != nil {
+			continue
+		}
+		width += fromFixed(advance)
+	}
+	return width
+}
+
+// textOutline returns the glyphs of the text centered at x with the baseline
+// at y. Runes missing from the font are skipped.
+func textOutline(f *sfnt.Font, b *sfnt.Buffer, text string, size, x, y float64) *outline {
+	o := &outline{}
+	pen := x - textWidth(f, b, text, size)/2
+	for _, r := range text {
+		idx, err := f.GlyphIndex(b, r)
+		if err != nil || idx == 0 {
+			continue
+		}
+		segments, err := f.LoadGlyph(b, idx, toFixed(size), nil)
+		if err != nil {
+			continue
+		}
+		// the Y axis of glyph segments points down like in SVG
+		at := func(p fixed.Point26_6) Point {
+			return Point{X: pen + fromFixed(p.X), Y: y + fromFixed(p.Y)}
+		}
+		var current Point
+		for k, s := range segments {
+			switch s.Op {
+			case sfnt.SegmentOpMoveTo:
+				if k > 0 {
+					o.segments = append(o.segments, segment{kind: segmentClose})
+				}
+				current = at(s.Args[0])
+				o.segments = append(o.segments, segment{kind: segmentMove, pts: [3]Point{{}, {}, current}})
+			case sfnt.SegmentOpLineTo:
+				current = at(s.Args[0])
+				o.segments = append(o.segments, segment{kind: segmentLine, pts: [3]Point{{}, {}, current}})
+			case sfnt.SegmentOpQuadTo:
+				// raise the quadratic curve to a cubic one
+				ctrl, end := at(s.Args[0]), at(s.Args[1])
+				o.segments = append(o.segments, segment{kind: segmentCubic, pts: [3]Point{
+					{X: current.X + 2.0/3*(ctrl.X-current.X), Y: current.Y + 2.0/3*(ctrl.Y-current.Y)},
+					{X: end.X + 2.0/3*(ctrl.X-end.X), Y: end.Y + 2.0/3*(ctrl.Y-end.Y)},
+					end,
+				}})
+				current = end
+			case sfnt.SegmentOpCubeTo:
+				current = at(s.Args[2])
+				o.segments = append(o.segments, segment{kind: segmentCubic, pts: [3]Point{
+					at(s.Args[0]), at(s.Args[1]), current,
+				}})
+			}
+		}
+		if len(segments) > 0 {
+			o.segments = append(o.segments, segment{kind: segmentClose})
+		}
+		advance, err := f.GlyphAdvance(b, idx, toFixed(size), font.HintingNone)
+		if err == nil {
+			pen += fromFixed(advance)
+		}
+	}
+	return o
+}
+
+func toFixed(v float64) fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(v * 64))
+}
+
+func fromFixed(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,65 @@
+package jdenticon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	const (
+		england = "\U0001f3f4\U000e0067\U000e0062\U000e0065\U000e006e\U000e0067\U000e007f"
+		family  = "\U0001f468‍\U0001f469‍\U0001f467"
+		couple  = "\U0001f469‍❤️‍\U0001f468"
+	)
+	tests := []struct {
+		name string
+		s    string
+		want []string
+	}{
+		{"ascii", "ab", []string{"a", "b"}},
+		{"crlf", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"combining mark", "éa", []string{"é", "a"}},
+		{"combining marks", "ạ̈b", []string{"ạ̈", "b"}},
+		{"zwj family", family + "x", []string{family, "x"}},
+		{"zwj with variation selector", couple, []string{couple}},
+		{"zwj between letters", "a‍b", []string{"a‍", "b"}},
+		{"skin tone", "\U0001f44d\U0001f3fd\U0001f44d", []string{"\U0001f44d\U0001f3fd", "\U0001f44d"}},
+		{"flags", "\U0001f1fa\U0001f1f8\U0001f1e9\U0001f1ea", []string{"\U0001f1fa\U0001f1f8", "\U0001f1e9\U0001f1ea"}},
+		{"odd regional indicators", "\U0001f1fa\U0001f1f8\U0001f1e9", []string{"\U0001f1fa\U0001f1f8", "\U0001f1e9"}},
+		{"subdivision flag", england + "a", []string{england, "a"}},
+		{"hangul syllables", "한글", []string{"한", "글"}},
+		{"hangul jamo", "한그", []string{"한", "그"}},
+		{"hangul open syllable and trail", "각가", []string{"각", "가"}},
+		{"hangul closed syllable and trail", "각ᆨ", []string{"각ᆨ"}},
+		{"hangul closed syllable and vowel", "각ᅡ", []string{"각", "ᅡ"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graphemes(tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("graphemes(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitialsOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", ""},
+		{"ada", "A"},
+		{"ada lovelace", "AL"},
+		{"Ada Augusta King-Lovelace", "AL"},
+		{"émile zola", "ÉZ"},
+		{"émile zola", "ÉZ"},
+		{"\U0001f469‍\U0001f4bb dev", "\U0001f469‍\U0001f4bbD"},
+		{"\U0001f1eb\U0001f1f7 paris", "\U0001f1eb\U0001f1f7P"},
+		{"홍길동", "홍"},
+	}
+	for _, tt := range tests {
+		if got := initialsOf(tt.name); got != tt.want {
+			t.Errorf("initialsOf(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,9 +2,13 @@ package jdenticon
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/sha1" // nolint:gosec
+	"encoding/binary"
 	"encoding/hex"
 	"html/template"
+	"image"
+	"io"
 	"math/rand"
 	"time"
 )
@@ -18,6 +22,42 @@ type Jdenticon interface {
 	SVG() ([]byte, error)
 }
 
+// The icons returned by New, NewWithConfig and Initials implement the
+// following interfaces besides Jdenticon.
+
+// Rasterizer draws an icon as image.
+type Rasterizer interface {
+	Image() (image.Image, error)
+}
+
+// Encoder writes an icon in other file formats than SVG.
+type Encoder interface {
+	PNG(w io.Writer) error
+	ICO(w io.Writer, sizes ...int) error
+	VectorDrawable(w io.Writer) error
+	PDF(w io.Writer) error
+}
+
+// Printer prints an icon to a terminal.
+type Printer interface {
+	Terminal(w io.Writer, size int, mode TerminalMode) error
+	Sixel(w io.Writer) error
+	Kitty(w io.Writer) error
+}
+
+// Simulator shows an icon as seen with a color vision deficiency.
+type Simulator interface {
+	Simulate(d Deficiency) Jdenticon
+}
+
+// nolint:gochecknoglobals
+var (
+	_ Rasterizer = (*jdenticon)(nil)
+	_ Encoder    = (*jdenticon)(nil)
+	_ Printer    = (*jdenticon)(nil)
+	_ Simulator  = (*jdenticon)(nil)
+)
+
 type jdenticon struct {
 	config *Config
 	svg    *SVG
@@ -26,6 +66,12 @@ type jdenticon struct {
 	geometry Point
 	paddings Point
 	zero     Point
+	grids    []grid
+
+	// initials replace the shapes when not nil
+	initials *string
+	// simulated is the deficiency the colors are seen with, 0 for none
+	simulated Deficiency
 }
 
 func New(identity string) Jdenticon {
@@ -33,6 +79,30 @@ func New(identity string) Jdenticon {
 }
 
 func NewWithConfig(identity string, c *Config) Jdenticon {
+	return newJdenticon(c.hashIdentity(identity), c)
+}
+
+func hashIdentity(identity string) string {
+	return sha1hash2string(sha1.Sum([]byte(identity))) // nolint:gosec
+}
+
+// hashIdentity returns the hash of the identity with the salt of the config.
+// Salted hashes are HMACs keyed by the salt, so no salted identity shares the
+// hash of another identity without salt.
+func (c *Config) hashIdentity(identity string) string {
+	if c.Salt == 0 {
+		return hashIdentity(identity)
+	}
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], uint64(c.Salt))
+	mac := hmac.New(sha1.New, key[:])
+	_, _ = mac.Write([]byte(identity))
+	var sum [sha1.Size]byte
+	copy(sum[:], mac.Sum(nil))
+	return sha1hash2string(sum)
+}
+
+func newJdenticon(hash string, c *Config) *jdenticon {
 	w := float64(c.Width)
 	h := float64(c.Height)
 	j := &jdenticon{
@@ -41,16 +111,17 @@ func NewWithConfig(identity string, c *Config) Jdenticon {
 			Width:  c.Width,
 			Height: c.Height,
 		},
-		hash: sha1hash2string(sha1.Sum([]byte(identity))), // nolint:gosec
+		hash: hash,
 	}
+	j.svg.clip(c, hash)
 
 	j.geometry = Point{
 		X: float64(j.config.Width),
 		Y: float64(j.config.Height),
 	}
 	j.paddings = Point{
-		X: j.geometry.X * (c.Padding * 2),
-		Y: j.geometry.Y * (c.Padding * 2),
+		X: j.geometry.X * (c.padding() * 2),
+		Y: j.geometry.Y * (c.padding() * 2),
 	}
 	if j.geometry.X > j.geometry.Y {
 		j.paddings.X += j.geometry.X - j.geometry.Y
@@ -62,62 +133,81 @@ func NewWithConfig(identity string, c *Config) Jdenticon {
 		X: j.paddings.X / 2,
 		Y: j.paddings.Y / 2,
 	}
+	j.grids = j.fit()
 
-	if opacity(c.Background) != 0.0 {
+	if fill, alpha := j.background(); alpha != 0.0 {
 		j.svg.Paths = append(j.svg.Paths, Path{
-			Fill:       toHex(c.Background),
+			Fill:       fill,
 			UseOpacity: true,
-			Opacity:    opacity(c.Background),
+			Opacity:    alpha,
 			Shapes: Shapes{
 				&Polygon{[]Point{{0, 0}, {w, 0}, {w, h}, {0, h}}, false},
 			},
 		})
 	}
+	first := len(j.svg.Paths)
+	if c.Style == StyleBlocks {
+		j.renderBlocks()
+		j.decorate(first)
+		return j
+	}
 	colors := j.colors()
 	shapes := map[string]Shapes{}
-	shapes[colors[0]] = Shapes{}
-	shapes[colors[1]] = Shapes{}
-	shapes[colors[2]] = Shapes{}
-	shapes[colors[0]] = append(shapes[colors[0]], j.renderShapes(shapeOuter, 2, 3, [][2]float64{
-		{1, 0},
-		{2, 0},
-		{2, 3},
-		{1, 3},
-		{0, 1},
-		{3, 1},
-		{3, 2},
-		{0, 2},
-	})...)
-	shapes[colors[1]] = append(shapes[colors[1]], j.renderShapes(shapeOuter, 4, 5, [][2]float64{
-		{0, 0},
-		{3, 0},
-		{3, 3},
-		{0, 3},
-	})...)
-	shapes[colors[2]] = append(shapes[colors[2]], j.renderShapes(shapeInner, 1, 0, [][2]float64{
-		{1, 1},
-		{2, 1},
-		{2, 2},
-		{1, 2},
-	})...)
-
-	for color := range shapes {
+	order := []string{}
+	for _, slot := range c.layout().Slots {
+		set := c.outer()
+		if slot.Inner {
+			set = c.inner()
+		}
+		color := colors[slot.Color]
+		if _, ok := shapes[color]; !ok {
+			order = append(order, color)
+		}
+		shapes[color] = append(shapes[color], j.renderShapes(set, slot.Shape, slot.Rotation, slot.Positions)...)
+	}
+
+	for _, color := range order {
 		j.svg.Paths = append(j.svg.Paths, Path{
 			Fill:   color,
 			Shapes: shapes[color],
 		})
 	}
+	j.decorate(first)
 	return j
 }
 
+// resize renders the same identity again at another size.
+func (j *jdenticon) resize(width, height int) *jdenticon {
+	c := *j.config
+	c.Width = width
+	c.Height = height
+	var r *jdenticon
+	if j.initials != nil {
+		r = newInitials(j.hash, *j.initials, &c)
+	} else {
+		r = newJdenticon(j.hash, &c)
+	}
+	if j.simulated != 0 {
+		return r.simulate(j.simulated)
+	}
+	return r
+}
+
 func (j *jdenticon) SVG() ([]byte, error) {
-	t, err := template.New("svg").Parse(tmpl)
+	return executeTemplate(tmpl, j.svg.withCSSColors())
+}
+
+func executeTemplate(text string, data interface{}) ([]byte, error) {
+	t, err := template.New("svg").Parse(text)
 	if err != nil {
 		return nil, err
 	}
+	if _, err = t.Parse(pathsTmpl); err != nil {
+		return nil, err
+	}
 	var b []byte
 	buf := bytes.NewBuffer(b)
-	err = t.Execute(buf, j.svg)
+	err = t.Execute(buf, data)
 	return buf.Bytes(), err
 }
 
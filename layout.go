@@ -0,0 +1,195 @@
+package jdenticon
+
+import (
+	"crypto/sha1" // nolint:gosec
+	"fmt"
+)
+
+// number of hex digits in the hash of an identity
+const hashDigits = 2 * sha1.Size
+
+// Layout describes the grid of an identicon and how its cells are grouped
+// into slots. All cells of a slot show the same shape in the same color.
+type Layout struct {
+	// Size is the number of cells along each edge of the grid.
+	Size  int
+	Slots []Slot
+}
+
+// Slot is a group of cells rendered with one shape.
+type Slot struct {
+	Name string
+	// Inner selects the inner shape set instead of the outer one.
+	Inner bool
+	// Color is the index of the layer color, from 0 to 2.
+	Color int
+	// Shape is the position of the hash digit selecting the shape, from 1.
+	Shape int
+	// Rotation is the position of the hash digit selecting the rotation of
+	// the first cell, 0 starts unrotated. Every following cell is rotated by
+	// another 90 degrees.
+	Rotation int
+	// Positions are the cells of the slot as column and row.
+	Positions [][2]int
+}
+
+// ClassicLayout is the 4x4 layout of Jdenticon.
+var ClassicLayout = &Layout{ // nolint:gochecknoglobals
+	Size: 4,
+	Slots: []Slot{
+		{
+			Name:     "sides",
+			Color:    0,
+			Shape:    2,
+			Rotation: 3,
+			Positions: [][2]int{
+				{1, 0},
+				{2, 0},
+				{2, 3},
+				{1, 3},
+				{0, 1},
+				{3, 1},
+				{3, 2},
+				{0, 2},
+			},
+		},
+		{
+			Name:     "corners",
+			Color:    1,
+			Shape:    4,
+			Rotation: 5,
+			Positions: [][2]int{
+				{0, 0},
+				{3, 0},
+				{3, 3},
+				{0, 3},
+			},
+		},
+		{
+			Name:     "center",
+			Inner:    true,
+			Color:    2,
+			Shape:    1,
+			Rotation: 0,
+			Positions: [][2]int{
+				{1, 1},
+				{2, 1},
+				{2, 2},
+				{1, 2},
+			},
+		},
+	},
+}
+
+// hash digits free for slots, the others select the colors and the hue
+// nolint:gochecknoglobals
+var layoutDigits = []int{
+	1, 2, 3, 4, 5, 6, 7,
+	11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32,
+}
+
+// NewGridLayout creates a layout of size x size cells with rotational
+// symmetry. Every ring of the grid gets a slot for its corners and one slot
+// per pair of opposite side cells, so larger grids show more distinct shapes.
+// The outer ring uses the outer shapes, all other rings the inner ones.
+func NewGridLayout(size int) (*Layout, error) {
+	if size < 3 {
+		return nil, fmt.Errorf("invalid layout size %d", size)
+	}
+	l := &Layout{Size: size}
+	digit := 0
+	next := func() int {
+		d := layoutDigits[digit%len(layoutDigits)]
+		digit++
+		return d
+	}
+	add := func(name string, inner bool, cells ...[2]int) {
+		slot := Slot{
+			Name:  name,
+			Inner: inner,
+			Color: len(l.Slots) % 3,
+			Shape: next(),
+		}
+		if !inner {
+			slot.Rotation = next()
+		}
+		for _, cell := range cells {
+			slot.Positions = append(slot.Positions, orbit(cell, size)...)
+		}
+		l.Slots = append(l.Slots, slot)
+	}
+	for ring := 0; 2*ring < size; ring++ {
+		inner := ring > 0
+		name := fmt.Sprintf("ring %d ", ring)
+		if ring == 0 {
+			name = ""
+		}
+		if 2*(ring+1) >= size {
+			add("center", inner, [2]int{ring, ring})
+			break
+		}
+		for k := ring + 1; k <= size-1-k; k++ {
+			sides := "sides"
+			if k > ring+1 {
+				sides = fmt.Sprintf("sides %d", k-ring)
+			}
+			if k == size-1-k {
+				add(name+sides, inner, [2]int{k, ring})
+			} else {
+				add(name+sides, inner, [2]int{k, ring}, [2]int{size - 1 - k, ring})
+			}
+		}
+		add(name+"corners", inner, [2]int{ring, ring})
+	}
+	return l, l.Validate()
+}
+
+// Validate checks that the slots use layer colors 0 to 2, hash digits within
+// the hash, shape digits from 1 and cells inside the grid.
+func (l *Layout) Validate() error {
+	if l.Size < 1 {
+		return fmt.Errorf("invalid layout size %d", l.Size)
+	}
+	for _, slot := range l.Slots {
+		if slot.Color < 0 || slot.Color > 2 {
+			return fmt.Errorf("slot %q: color %d out of range 0 to 2", slot.Name, slot.Color)
+		}
+		// digit 0 makes the last built-in inner shape a circle spanning the
+		// neighboring cells
+		if slot.Shape < 1 || slot.Shape >= hashDigits {
+			return fmt.Errorf("slot %q: shape digit %d out of range 1 to %d", slot.Name, slot.Shape, hashDigits-1)
+		}
+		if slot.Rotation < 0 || slot.Rotation >= hashDigits {
+			return fmt.Errorf("slot %q: rotation digit %d out of range 0 to %d", slot.Name, slot.Rotation, hashDigits-1)
+		}
+		for _, p := range slot.Positions {
+			if p[0] < 0 || p[0] >= l.Size || p[1] < 0 || p[1] >= l.Size {
+				return fmt.Errorf("slot %q: cell %v outside the %dx%d grid", slot.Name, p, l.Size, l.Size)
+			}
+		}
+	}
+	return nil
+}
+
+// orbit returns the cell followed by its images when rotating the grid
+// clockwise by 90, 180 and 270 degrees, without duplicates.
+func orbit(cell [2]int, size int) [][2]int {
+	cells := [][2]int{cell}
+	for i := 0; i < 3; i++ {
+		cell = [2]int{size - 1 - cell[1], cell[0]}
+		if cell == cells[0] {
+			break
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// layout returns the layout of the config, ClassicLayout when it is nil or
+// invalid.
+func (c *Config) layout() *Layout {
+	if c.Layout == nil || c.Layout.Validate() != nil {
+		return ClassicLayout
+	}
+	return c.Layout
+}
@@ -0,0 +1,75 @@
+package jdenticon
+
+import "testing"
+
+func TestLayoutValidate(t *testing.T) {
+	cell := [][2]int{{0, 0}}
+	tests := []struct {
+		name  string
+		slot  Slot
+		valid bool
+	}{
+		{"valid", Slot{Color: 2, Shape: 39, Rotation: 39, Positions: [][2]int{{3, 3}}}, true},
+		{"color", Slot{Color: 3, Shape: 1, Positions: cell}, false},
+		{"shape", Slot{Shape: 40, Positions: cell}, false},
+		// the last inner shape spans the neighboring cells at digit 0
+		{"shape 0", Slot{Inner: true, Positions: cell}, false},
+		{"rotation", Slot{Shape: 1, Rotation: 41, Positions: cell}, false},
+		{"position", Slot{Shape: 1, Positions: [][2]int{{4, 0}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Layout{Size: 4, Slots: []Slot{tt.slot}}
+			if err := l.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+			// invalid layouts fall back to the classic one instead of panicking
+			c := *DefaultConfig
+			c.Layout = l
+			if _, err := NewWithConfig("layout", &c).SVG(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	for size := 3; size < 12; size++ {
+		if _, err := NewGridLayout(size); err != nil {
+			t.Errorf("NewGridLayout(%d) = %v", size, err)
+		}
+	}
+}
+
+func TestLayoutCellBounds(t *testing.T) {
+	layouts := []*Layout{ClassicLayout}
+	for size := 3; size < 9; size++ {
+		l, err := NewGridLayout(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		layouts = append(layouts, l)
+	}
+	const eps = 1e-9
+	for _, l := range layouts {
+		c := *DefaultConfig
+		c.Width, c.Height, c.Padding = 120, 120, 0
+		c.Layout = l
+		j := newJdenticon(hashIdentity("bounds"), &c)
+		g := j.grids[0]
+		cell := g.size.X / float64(l.Size)
+		for _, slot := range l.Slots {
+			set := OuterShapes
+			if slot.Inner {
+				set = InnerShapes
+			}
+			// every shape of the set is rendered in turn at every position
+			for shape, fn := range set {
+				for _, pos := range slot.Positions {
+					b := j.renderShapes(ShapeSet{fn}, slot.Shape, slot.Rotation, [][2]int{pos}).Bounds()
+					min := Point{X: g.origin.X + float64(pos[0])*cell, Y: g.origin.Y + float64(pos[1])*cell}
+					if b.Min.X < min.X-eps || b.Min.Y < min.Y-eps || b.Max.X > min.X+cell+eps || b.Max.Y > min.Y+cell+eps {
+						t.Errorf("%dx%d slot %q shape %d at %v: bounds %v outside the cell", l.Size, l.Size, slot.Name, shape, pos, b)
+					}
+				}
+			}
+		}
+	}
+}
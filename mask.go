@@ -0,0 +1,131 @@
+package jdenticon
+
+import (
+	"math"
+)
+
+// Mask clips an icon to the shape of an avatar. SVG images get a clip path,
+// the other formats are cut out, so the shape also shows where CSS clipping
+// doesn't apply, as in emails and PNG files.
+type Mask interface {
+	// Outline returns the visible area of an icon of the size.
+	Outline(width, height float64) Shapes
+	// Inset returns the padding, relative to the size of the icon, that keeps
+	// a centered square inside the visible area.
+	Inset() float64
+}
+
+// CircleMask shows the circle, or ellipse, touching the edges of the icon.
+type CircleMask struct{}
+
+func (CircleMask) Outline(width, height float64) Shapes {
+	return Shapes{&Ellipse{
+		Center:  Point{X: width / 2, Y: height / 2},
+		RadiusX: width / 2,
+		RadiusY: height / 2,
+	}}
+}
+
+func (CircleMask) Inset() float64 {
+	return (1 - 1/math.Sqrt2) / 2
+}
+
+// RoundedMask shows the icon with rounded corners.
+type RoundedMask struct {
+	// Radius of the corners relative to the smaller side, up to 0.5.
+	Radius float64
+}
+
+func (m RoundedMask) Outline(width, height float64) Shapes {
+	r := m.radius() * math.Min(width, height)
+	// distance of the control points approximating a quarter circle
+	k := r * 0.5523
+	line := func(x, y float64) segment {
+		return segment{kind: segmentLine, pts: [3]Point{{}, {}, {X: x, Y: y}}}
+	}
+	curve := func(x1, y1, x2, y2, x, y float64) segment {
+		return segment{kind: segmentCubic, pts: [3]Point{{X: x1, Y: y1}, {X: x2, Y: y2}, {X: x, Y: y}}}
+	}
+	return Shapes{&outline{segments: []segment{
+		{kind: segmentMove, pts: [3]Point{{}, {}, {X: r, Y: 0}}},
+		line(width-r, 0),
+		curve(width-r+k, 0, width, r-k, width, r),
+		line(width, height-r),
+		curve(width, height-r+k, width-r+k, height, width-r, height),
+		line(r, height),
+		curve(r-k, height, 0, height-r+k, 0, height-r),
+		line(0, r),
+		curve(0, r-k, r-k, 0, r, 0),
+		{kind: segmentClose},
+	}}}
+}
+
+func (m RoundedMask) Inset() float64 {
+	return m.radius() * (1 - 1/math.Sqrt2)
+}
+
+func (m RoundedMask) radius() float64 {
+	return math.Max(0, math.Min(m.Radius, 0.5))
+}
+
+// SquircleMask shows the superellipse |x|^4 + |y|^4 = 1 touching the edges
+// of the icon, a square with smoothly rounded corners.
+type SquircleMask struct{}
+
+// number of points approximating the squircle
+const squirclePoints = 64
+
+func (SquircleMask) Outline(width, height float64) Shapes {
+	points := make([]Point, 0, squirclePoints)
+	for i := 0; i < squirclePoints; i++ {
+		t := 2 * math.Pi * float64(i) / squirclePoints
+		cos, sin := math.Cos(t), math.Sin(t)
+		points = append(points, Point{
+			X: width / 2 * (1 + math.Copysign(math.Sqrt(math.Abs(cos)), cos)),
+			Y: height / 2 * (1 + math.Copysign(math.Sqrt(math.Abs(sin)), sin)),
+		})
+	}
+	return Shapes{&Polygon{Points: points, Clockwise: true}}
+}
+
+func (SquircleMask) Inset() float64 {
+	return (1 - math.Pow(2, -0.25)) / 2
+}
+
+// HexagonMask shows a hexagon with flat top and bottom edges touching the
+// edges of the icon.
+type HexagonMask struct{}
+
+func (HexagonMask) Outline(width, height float64) Shapes {
+	return Shapes{&Polygon{Points: []Point{
+		{X: width / 4, Y: 0},
+		{X: width * 3 / 4, Y: 0},
+		{X: width, Y: height / 2},
+		{X: width * 3 / 4, Y: height},
+		{X: width / 4, Y: height},
+		{X: 0, Y: height / 2},
+	}, Clockwise: true}}
+}
+
+func (HexagonMask) Inset() float64 {
+	return 1.0 / 6
+}
+
+// padding returns Config.Padding applied within the visible area of the mask.
+func (c *Config) padding() float64 {
+	if c.Mask == nil {
+		return c.Padding
+	}
+	inset := c.Mask.Inset()
+	return inset + c.Padding*(1-2*inset)
+}
+
+// clip sets the clip path of the SVG to the mask of the config.
+func (s *SVG) clip(c *Config, hash string) {
+	if c.Mask == nil {
+		return
+	}
+	s.Clip = c.Mask.Outline(float64(s.Width), float64(s.Height))
+	// icons of one identity may differ in size and mask on the same page
+	s.ClipID = "jdenticon-mask-" + hashIdentity(hash + s.Clip.String())[:8]
+}
@@ -0,0 +1,111 @@
+package jdenticon
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// Palette is an ordered list of colors replacing the colors derived from the
+// hue. Five of them are picked by the hash of the identity.
+type Palette []color.Color
+
+// Built-in palettes, also available by name from PaletteByName.
+// nolint:gochecknoglobals
+var (
+	// MaterialPalette holds the 500 shades of the Material Design colors.
+	MaterialPalette = hexPalette(
+		"#f44336", "#e91e63", "#9c27b0", "#673ab7", "#3f51b5", "#2196f3", "#03a9f4",
+		"#00bcd4", "#009688", "#4caf50", "#8bc34a", "#cddc39", "#ffeb3b", "#ffc107",
+		"#ff9800", "#ff5722", "#795548", "#9e9e9e", "#607d8b",
+	)
+	// TailwindPalette holds Tailwind-like 500 shades with a dark and a light
+	// slate.
+	TailwindPalette = hexPalette(
+		"#1e293b", "#64748b", "#e2e8f0", "#ef4444", "#f97316", "#f59e0b", "#eab308",
+		"#84cc16", "#22c55e", "#10b981", "#14b8a6", "#06b6d4", "#0ea5e9", "#3b82f6",
+		"#6366f1", "#8b5cf6", "#a855f7", "#d946ef", "#ec4899", "#f43f5e",
+	)
+	// PastelPalette holds soft light colors.
+	PastelPalette = hexPalette(
+		"#ffadad", "#ffd6a5", "#fdffb6", "#caffbf", "#9bf6ff", "#a0c4ff", "#bdb2ff",
+		"#ffc6ff", "#d4d4d4",
+	)
+	// HighContrastPalette holds black, white and the Okabe-Ito colors, which
+	// stay apart for color blind viewers.
+	HighContrastPalette = hexPalette(
+		"#000000", "#ffffff", "#e69f00", "#56b4e9", "#009e73", "#f0e442", "#0072b2",
+		"#d55e00", "#cc79a7",
+	)
+)
+
+// PaletteByName returns the built-in palette "material", "tailwind",
+// "pastel" or "high-contrast".
+func PaletteByName(name string) (Palette, error) {
+	switch name {
+	case "material":
+		return MaterialPalette, nil
+	case "tailwind":
+		return TailwindPalette, nil
+	case "pastel":
+		return PastelPalette, nil
+	case "high-contrast":
+		return HighContrastPalette, nil
+	}
+	return nil, fmt.Errorf("unknown palette %q", name)
+}
+
+func hexPalette(colors ...string) Palette {
+	p := make(Palette, 0, len(colors))
+	for _, hex := range colors {
+		c, err := colorful.Hex(hex)
+		if err != nil {
+			panic(err)
+		}
+		p = append(p, c)
+	}
+	return p
+}
+
+// paletteTheme picks five colors of the palette with the hue digits of the
+// hash and puts them in the order of theme(): the darkest two go to the dark
+// gray and dark color entries and the lightest two to the light ones, so the
+// deduplication in colors() still avoids two dark or two light layers. The
+// dark variant inverts their lightness like the colors derived from the hue.
+func (j *jdenticon) paletteTheme() []string {
+	p := j.config.Palette
+	v, _ := strconv.ParseInt(j.hash[len(j.hash)-7:], 16, 64)
+	if j.uniform() {
+		v = j.uniformHue()
+	}
+	indices := make([]int, len(p))
+	for i := range indices {
+		indices[i] = i
+	}
+	picked := make([]colorful.Color, 5)
+	for k := range picked {
+		// pick without repetition while the palette lasts
+		n := len(indices) - k%len(indices)
+		i := k%len(indices) + int(v%int64(n))
+		v /= int64(n)
+		start := k % len(indices)
+		indices[start], indices[i] = indices[i], indices[start]
+		picked[k], _ = colorful.MakeColor(p[indices[start]])
+	}
+	sort.SliceStable(picked, func(a, b int) bool {
+		return relativeLuminance(picked[a]) < relativeLuminance(picked[b])
+	})
+	for k := range picked {
+		picked[k] = j.config.Variant.color(picked[k])
+	}
+	return []string{
+		picked[0].Hex(), // dark gray
+		picked[2].Hex(), // mid color
+		picked[4].Hex(), // light gray
+		picked[3].Hex(), // light color
+		picked[1].Hex(), // dark color
+	}
+}
@@ -0,0 +1,411 @@
+package jdenticon
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Every non-SVG backend consumes the same path data that ends up in the SVG
+// "d" attribute, so all outputs stay identical down to the rounding.
+
+type segmentKind int
+
+const (
+	segmentMove segmentKind = iota
+	segmentLine
+	segmentCubic
+	segmentClose
+)
+
+// segment is a single absolute drawing command. Cubic segments use all three
+// points (two control points and the end point), the others only the last one.
+type segment struct {
+	kind segmentKind
+	pts  [3]Point
+}
+
+func (s segment) end() Point {
+	return s.pts[2]
+}
+
+func parsePathData(d string) ([]segment, error) {
+	p := &pathParser{data: d}
+	return p.parse()
+}
+
+type pathParser struct {
+	data string
+	pos  int
+
+	segments []segment
+	current  Point
+	start    Point
+}
+
+func (p *pathParser) parse() ([]segment, error) {
+	var cmd byte
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.data) {
+			return p.segments, nil
+		}
+		c := p.data[p.pos]
+		switch {
+		case isPathCommand(c):
+			cmd = c
+			p.pos++
+		case cmd == 0:
+			return nil, fmt.Errorf("path data must start with a command at %d", p.pos)
+		case cmd == 'M':
+			// subsequent pairs of a moveto are implicit linetos
+			cmd = 'L'
+		case cmd == 'm':
+			cmd = 'l'
+		case cmd == 'Z' || cmd == 'z':
+			return nil, fmt.Errorf("unexpected number after closepath at %d", p.pos)
+		}
+		if err := p.command(cmd); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *pathParser) command(cmd byte) error { // nolint:gocyclo
+	relative := cmd >= 'a'
+	base := Point{}
+	if relative {
+		base = p.current
+	}
+	switch cmd {
+	case 'M', 'm':
+		pt, err := p.point(base)
+		if err != nil {
+			return err
+		}
+		p.start = pt
+		p.emit(segment{kind: segmentMove, pts: [3]Point{{}, {}, pt}})
+	case 'L', 'l':
+		pt, err := p.point(base)
+		if err != nil {
+			return err
+		}
+		p.emit(segment{kind: segmentLine, pts: [3]Point{{}, {}, pt}})
+	case 'H', 'h':
+		x, err := p.number()
+		if err != nil {
+			return err
+		}
+		p.emit(segment{kind: segmentLine, pts: [3]Point{{}, {}, {X: base.X + x, Y: p.current.Y}}})
+	case 'V', 'v':
+		y, err := p.number()
+		if err != nil {
+			return err
+		}
+		p.emit(segment{kind: segmentLine, pts: [3]Point{{}, {}, {X: p.current.X, Y: base.Y + y}}})
+	case 'C', 'c':
+		var pts [3]Point
+		for i := range pts {
+			pt, err := p.point(base)
+			if err != nil {
+				return err
+			}
+			pts[i] = pt
+		}
+		p.emit(segment{kind: segmentCubic, pts: pts})
+	case 'A', 'a':
+		var v [5]float64
+		for i := range v {
+			n, err := p.number()
+			if err != nil {
+				return err
+			}
+			v[i] = n
+		}
+		end, err := p.point(base)
+		if err != nil {
+			return err
+		}
+		for _, s := range arcToCubics(p.current, end, v[0], v[1], v[2], v[3] != 0, v[4] != 0) {
+			p.emit(s)
+		}
+		p.current = end
+	case 'Z', 'z':
+		p.emit(segment{kind: segmentClose, pts: [3]Point{{}, {}, p.start}})
+	default:
+		return fmt.Errorf("unsupported path command %q", cmd)
+	}
+	return nil
+}
+
+func (p *pathParser) emit(s segment) {
+	p.segments = append(p.segments, s)
+	p.current = s.end()
+}
+
+func (p *pathParser) point(base Point) (Point, error) {
+	x, err := p.number()
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := p.number()
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{X: base.X + x, Y: base.Y + y}, nil
+}
+
+func (p *pathParser) number() (float64, error) {
+	p.skipSpaces()
+	start := p.pos
+	if p.pos < len(p.data) && (p.data[p.pos] == '-' || p.data[p.pos] == '+') {
+		p.pos++
+	}
+	dot := false
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '.' && !dot {
+			dot = true
+		} else if c < '0' || c > '9' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '-' || p.data[p.pos] == '+') {
+			p.pos++
+		}
+		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			p.pos++
+		}
+	}
+	n, err := strconv.ParseFloat(p.data[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number in path data at %d", start)
+	}
+	return n, nil
+}
+
+func (p *pathParser) skipSpaces() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', ',', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func isPathCommand(c byte) bool {
+	switch c {
+	case 'M', 'm', 'L', 'l', 'H', 'h', 'V', 'v', 'C', 'c', 'A', 'a', 'Z', 'z':
+		return true
+	}
+	return false
+}
+
+// arcToCubics converts an SVG elliptical arc to cubic Bézier segments, see
+// https://www.w3.org/TR/SVG/implnote.html#ArcImplementationNotes
+func arcToCubics(from, to Point, rx, ry, deg float64, large, sweep bool) []segment {
+	if from == to {
+		return nil
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return []segment{{kind: segmentLine, pts: [3]Point{{}, {}, to}}}
+	}
+	phi := deg * math.Pi / 180
+	sin, cos := math.Sin(phi), math.Cos(phi)
+	dx, dy := (from.X-to.X)/2, (from.Y-to.Y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx *= math.Sqrt(l)
+		ry *= math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		k = -k
+	}
+	cx1 := k * rx * y1 / ry
+	cy1 := -k * ry * x1 / rx
+	center := Point{
+		X: cos*cx1 - sin*cy1 + (from.X+to.X)/2,
+		Y: sin*cx1 + cos*cy1 + (from.Y+to.Y)/2,
+	}
+	theta := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	at := func(t float64) (Point, Point) {
+		st, ct := math.Sin(t), math.Cos(t)
+		p := Point{
+			X: center.X + cos*rx*ct - sin*ry*st,
+			Y: center.Y + sin*rx*ct + cos*ry*st,
+		}
+		d := Point{
+			X: -cos*rx*st - sin*ry*ct,
+			Y: -sin*rx*st + cos*ry*ct,
+		}
+		return p, d
+	}
+	n := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	if n < 1 {
+		n = 1
+	}
+	step := delta / float64(n)
+	alpha := 4.0 / 3 * math.Tan(step/4)
+	result := make([]segment, 0, n)
+	p1, d1 := at(theta)
+	for i := 1; i <= n; i++ {
+		p2, d2 := at(theta + step*float64(i))
+		if i == n {
+			p2 = to
+		}
+		result = append(result, segment{kind: segmentCubic, pts: [3]Point{
+			{X: p1.X + alpha*d1.X, Y: p1.Y + alpha*d1.Y},
+			{X: p2.X - alpha*d2.X, Y: p2.Y - alpha*d2.Y},
+			p2,
+		}})
+		p1, d1 = p2, d2
+	}
+	return result
+}
+
+// flatten turns path segments into closed polygons approximating the curves
+// with line segments no longer than tolerance.
+func flatten(segments []segment, tolerance float64) [][]Point {
+	var (
+		contours [][]Point
+		current  []Point
+	)
+	last := Point{}
+	flush := func() {
+		if len(current) > 1 {
+			contours = append(contours, current)
+		}
+		current = nil
+	}
+	for _, s := range segments {
+		switch s.kind {
+		case segmentMove:
+			flush()
+			current = []Point{s.end()}
+		case segmentLine:
+			if current == nil {
+				current = []Point{last}
+			}
+			current = append(current, s.end())
+		case segmentCubic:
+			if current == nil {
+				current = []Point{last}
+			}
+			length := distance(last, s.pts[0]) + distance(s.pts[0], s.pts[1]) + distance(s.pts[1], s.pts[2])
+			n := int(math.Ceil(length / tolerance))
+			if n < 1 {
+				n = 1
+			}
+			for i := 1; i <= n; i++ {
+				current = append(current, cubicAt(last, s.pts[0], s.pts[1], s.pts[2], float64(i)/float64(n)))
+			}
+		case segmentClose:
+			flush()
+		}
+		last = s.end()
+	}
+	flush()
+	return contours
+}
+
+func cubicAt(p0, p1, p2, p3 Point, t float64) Point {
+	mt := 1 - t
+	a := mt * mt * mt
+	b := 3 * mt * mt * t
+	c := 3 * mt * t * t
+	d := t * t * t
+	return Point{
+		X: a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+		Y: a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+	}
+}
+
+func distance(a, b Point) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
+
+// outline is a shape made of path segments, like the glyphs of a font.
+type outline struct {
+	segments []segment
+}
+
+func (o *outline) Path() string {
+	var b strings.Builder
+	for _, s := range o.segments {
+		switch s.kind {
+		case segmentMove:
+			fmt.Fprintf(&b, "M%.2f,%.2f", s.pts[2].X, s.pts[2].Y)
+		case segmentLine:
+			fmt.Fprintf(&b, "L%.2f,%.2f", s.pts[2].X, s.pts[2].Y)
+		case segmentCubic:
+			fmt.Fprintf(&b, "C%.2f,%.2f %.2f,%.2f %.2f,%.2f",
+				s.pts[0].X, s.pts[0].Y, s.pts[1].X, s.pts[1].Y, s.pts[2].X, s.pts[2].Y)
+		case segmentClose:
+			b.WriteString("Z")
+		}
+	}
+	return b.String()
+}
+
+func (o *outline) Rotate(deg float64, center *Point) {
+	o.Transform(RotationMatrix(deg, center))
+}
+
+func (o *outline) Translate(dx, dy float64) {
+	o.Transform(TranslationMatrix(dx, dy))
+}
+
+func (o *outline) Scale(sx, sy float64, origin *Point) Shape {
+	return o.Transform(ScaleMatrix(sx, sy, origin))
+}
+
+func (o *outline) Mirror(axis Axis, origin *Point) {
+	o.Transform(MirrorMatrix(axis, origin))
+}
+
+func (o *outline) Transform(m Matrix) Shape {
+	for i := range o.segments {
+		for k := range o.segments[i].pts {
+			o.segments[i].pts[k].Transform(m)
+		}
+	}
+	return o
+}
+
+// Bounds returns the bounds of the end and control points, which contain the
+// curves.
+func (o *outline) Bounds() Rect {
+	r := emptyRect()
+	for _, s := range o.segments {
+		first := 2
+		if s.kind == segmentCubic {
+			first = 0
+		}
+		for k := first; k < len(s.pts); k++ {
+			r = r.Union(s.pts[k].Bounds())
+		}
+	}
+	return r
+}
+
+func (o *outline) Copy() Shape {
+	return &outline{segments: append([]segment(nil), o.segments...)}
+}
@@ -0,0 +1,135 @@
+package jdenticon
+
+import (
+	"image"
+	"io"
+	"strconv"
+)
+
+// number of motifs scattered over a pattern tile
+const patternMotifs = 7
+
+// PatternTile is a square tile of motifs that repeats seamlessly, drawn with
+// the colors and shapes of an identity.
+type PatternTile struct {
+	svg *SVG
+}
+
+type patternData struct {
+	ID  string
+	SVG *SVG
+}
+
+// Pattern creates the pattern tile of the identity with DefaultConfig.
+func Pattern(identity string, tileSize int) *PatternTile {
+	return PatternWithConfig(identity, tileSize, DefaultConfig)
+}
+
+// PatternWithConfig creates the pattern tile of the identity. The motifs are
+// picked from the shape sets of c at varying scales and rotations and colored
+// with the theme of the identity. Motifs crossing an edge of the tile are
+// repeated on the opposite edge.
+func PatternWithConfig(identity string, tileSize int, c *Config) *PatternTile {
+	hash := c.hashIdentity(identity)
+	j := newJdenticon(hash, c)
+	theme := j.theme()
+	size := float64(tileSize)
+	t := &PatternTile{svg: &SVG{Width: tileSize, Height: tileSize, p3: j.svg.p3}}
+
+	if fill, alpha := j.background(); alpha != 0.0 {
+		t.svg.Paths = append(t.svg.Paths, Path{
+			Fill:       fill,
+			UseOpacity: true,
+			Opacity:    alpha,
+			Shapes: Shapes{
+				&Polygon{[]Point{{0, 0}, {size, 0}, {size, size}, {0, size}}, false},
+			},
+		})
+	}
+
+	shapes := map[string]Shapes{}
+	order := []string{}
+	for k := 0; k < patternMotifs; k++ {
+		h := hashIdentity(hash + ":" + strconv.Itoa(k))
+		set := c.outer()
+		if hexDigits(h, 0, 1)%2 == 1 {
+			set = c.inner()
+		}
+		cell := size * (0.15 + 0.2*float64(hexDigits(h, 4, 2))/0xff)
+		center := Point{
+			X: size * float64(hexDigits(h, 6, 4)) / 0xffff,
+			Y: size * float64(hexDigits(h, 10, 4)) / 0xffff,
+		}
+		color := theme[hexDigits(h, 3, 1)%len(theme)]
+		getter := set[set.pick(hexDigits(h, 1, 1), h, 1)]
+		for _, shape := range getter(cell, 1) {
+			shape.Translate(center.X-cell/2, center.Y-cell/2)
+			shape.Rotate(float64(hexDigits(h, 2, 1)%4)*90, &center)
+			if _, ok := shapes[color]; !ok {
+				order = append(order, color)
+			}
+			shapes[color] = append(shapes[color], wrap(shape, size)...)
+		}
+	}
+	for _, color := range order {
+		t.svg.Paths = append(t.svg.Paths, Path{
+			Fill:   color,
+			Shapes: shapes[color],
+		})
+	}
+	return t
+}
+
+// wrap returns the shape together with its copies shifted by the tile size
+// that reach into the tile.
+func wrap(shape Shape, size float64) Shapes {
+	b := shape.Bounds()
+	result := Shapes{}
+	for _, dy := range []float64{0, -size, size} {
+		for _, dx := range []float64{0, -size, size} {
+			if b.Max.X+dx <= 0 || b.Min.X+dx >= size || b.Max.Y+dy <= 0 || b.Min.Y+dy >= size {
+				continue
+			}
+			s := shape.Copy()
+			s.Translate(dx, dy)
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// hexDigits parses n hex digits of the hash starting at pos.
+func hexDigits(hash string, pos, n int) int {
+	v, _ := strconv.ParseInt(hash[pos:pos+n], 16, 64)
+	return int(v)
+}
+
+// SVG returns a standalone SVG image of one tile.
+func (t *PatternTile) SVG() ([]byte, error) {
+	return executeTemplate(tmpl, t.svg.withCSSColors())
+}
+
+// PatternSVG returns the tile as an SVG <pattern> element with the given id,
+// to be referenced as fill="url(#id)".
+func (t *PatternTile) PatternSVG(id string) ([]byte, error) {
+	return executeTemplate(patternTmpl, patternData{ID: id, SVG: t.svg.withCSSColors()})
+}
+
+// Image renders one tile.
+func (t *PatternTile) Image() (image.Image, error) {
+	return rasterizeSVG(t.svg)
+}
+
+// PNG writes one tile as a PNG image.
+func (t *PatternTile) PNG(w io.Writer) error {
+	wide := len(t.svg.p3) > 0
+	svg := t.svg
+	if wide {
+		svg = svg.inDisplayP3()
+	}
+	img, err := rasterizeSVG(svg)
+	if err != nil {
+		return err
+	}
+	return encodePNG(w, img, wide)
+}
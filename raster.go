@@ -0,0 +1,346 @@
+package jdenticon
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// number of sub-scanlines sampled per pixel row; the horizontal coverage is
+// computed exactly
+const rasterSamples = 16
+
+// curves are flattened to segments of at most this many pixels
+const rasterTolerance = 0.25
+
+type edge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// rasterizePath computes the anti-aliased coverage of the path data d using
+// the nonzero fill rule, matching the SVG default.
+func rasterizePath(d string, width, height int) (*image.Alpha, error) {
+	segments, err := parsePathData(d)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizeContours(flatten(segments, rasterTolerance), width, height), nil
+}
+
+func rasterizeContours(contours [][]Point, width, height int) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	edges := []edge{}
+	for _, contour := range contours {
+		for i := range contour {
+			a := contour[i]
+			b := contour[(i+1)%len(contour)]
+			if a.Y == b.Y {
+				continue
+			}
+			if a.Y < b.Y {
+				edges = append(edges, edge{a.X, a.Y, b.X, b.Y, 1})
+			} else {
+				edges = append(edges, edge{b.X, b.Y, a.X, a.Y, -1})
+			}
+		}
+	}
+	if len(edges) == 0 {
+		return mask
+	}
+	coverage := make([]float64, width)
+	crossings := []crossing{}
+	for y := 0; y < height; y++ {
+		for x := range coverage {
+			coverage[x] = 0
+		}
+		for s := 0; s < rasterSamples; s++ {
+			sy := float64(y) + (float64(s)+0.5)/rasterSamples
+			crossings = crossings[:0]
+			for _, e := range edges {
+				if sy < e.y0 || sy >= e.y1 {
+					continue
+				}
+				x := e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+				crossings = append(crossings, crossing{x, e.dir})
+			}
+			sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+			winding := 0
+			for i, c := range crossings {
+				winding += c.dir
+				if winding != 0 && i+1 < len(crossings) {
+					addSpan(coverage, c.x, crossings[i+1].x)
+				}
+			}
+		}
+		for x, c := range coverage {
+			a := c / rasterSamples
+			if a > 1 {
+				a = 1
+			}
+			mask.Pix[y*mask.Stride+x] = uint8(a*255 + 0.5)
+		}
+	}
+	return mask
+}
+
+func addSpan(coverage []float64, a, b float64) {
+	w := float64(len(coverage))
+	a = math.Max(0, math.Min(a, w))
+	b = math.Max(0, math.Min(b, w))
+	if b <= a {
+		return
+	}
+	ia, ib := int(a), int(b)
+	if ia == ib {
+		coverage[ia] += b - a
+		return
+	}
+	coverage[ia] += float64(ia+1) - a
+	for i := ia + 1; i < ib; i++ {
+		coverage[i]++
+	}
+	if ib < len(coverage) {
+		coverage[ib] += b - float64(ib)
+	}
+}
+
+// Image renders the icon to an RGBA image of the configured size.
+func (j *jdenticon) Image() (image.Image, error) {
+	return j.rasterize()
+}
+
+func (j *jdenticon) rasterize() (*image.RGBA, error) {
+	return rasterizeSVG(j.svg)
+}
+
+func rasterizeSVG(svg *SVG) (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, svg.Width, svg.Height))
+	filters := map[string]Filter{}
+	for _, f := range svg.Filters {
+		filters[f.ID] = f
+	}
+	gradients := map[string]GradientFill{}
+	for _, g := range svg.Gradients {
+		gradients[g.ID] = g
+	}
+	for _, p := range svg.outlines() {
+		if p.UseOpacity && p.Opacity == 0 {
+			continue
+		}
+		alpha := 1.0
+		if p.UseOpacity {
+			alpha = p.Opacity
+		}
+		segments, err := parsePathData(p.Shapes.String())
+		if err != nil {
+			return nil, err
+		}
+		contours := flatten(segments, rasterTolerance)
+		var fill *image.Alpha
+		if p.Fill != "none" {
+			fill = rasterizeContours(contours, svg.Width, svg.Height)
+		}
+		var stroke *image.Alpha
+		if p.Stroke != "" && p.StrokeWidth > 0 {
+			stroke = rasterizeContours(strokeContours(contours, p.StrokeWidth), svg.Width, svg.Height)
+		}
+		if f, ok := filters[p.Filter]; ok {
+			if err := drawShadow(img, f, alpha, fill, stroke); err != nil {
+				return nil, err
+			}
+		}
+		if g, ok := gradients[p.Gradient]; ok && fill != nil {
+			src, err := newGradientImage(g, contoursBounds(contours), alpha)
+			if err != nil {
+				return nil, err
+			}
+			draw.DrawMask(img, img.Bounds(), src, image.Point{}, fill, image.Point{}, draw.Over)
+		} else if fill != nil {
+			if err := drawMask(img, p.Fill, alpha, fill); err != nil {
+				return nil, err
+			}
+		}
+		if stroke != nil {
+			if err := drawMask(img, p.Stroke, alpha, stroke); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(svg.Clip) == 0 {
+		return img, nil
+	}
+	clip, err := rasterizePath(svg.Clip.String(), svg.Width, svg.Height)
+	if err != nil {
+		return nil, err
+	}
+	clipped := image.NewRGBA(img.Bounds())
+	draw.DrawMask(clipped, clipped.Bounds(), img, image.Point{}, clip, image.Point{}, draw.Src)
+	return clipped, nil
+}
+
+func contoursBounds(contours [][]Point) Rect {
+	r := emptyRect()
+	for _, contour := range contours {
+		for _, p := range contour {
+			r = r.Union(p.Bounds())
+		}
+	}
+	return r
+}
+
+// drawMask paints the color hex through the mask.
+func drawMask(img *image.RGBA, hex string, alpha float64, mask *image.Alpha) error {
+	fill, err := colorful.Hex(hex)
+	if err != nil {
+		return err
+	}
+	c := color.NRGBA{R: 0, G: 0, B: 0, A: uint8(alpha*255 + 0.5)}
+	c.R, c.G, c.B = fill.RGB255()
+	draw.DrawMask(img, img.Bounds(), image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+	return nil
+}
+
+// drawShadow paints the shadow of the fill and stroke masks, like the SVG
+// feDropShadow filter primitive.
+func drawShadow(img *image.RGBA, f Filter, alpha float64, masks ...*image.Alpha) error {
+	b := img.Bounds()
+	shadow := image.NewAlpha(b)
+	for _, m := range masks {
+		if m != nil {
+			draw.Draw(shadow, b, m, image.Point{}, draw.Over)
+		}
+	}
+	shifted := image.NewAlpha(b)
+	draw.Draw(shifted, b, shadow, image.Point{X: -int(math.Round(f.DX)), Y: -int(math.Round(f.DY))}, draw.Src)
+	return drawMask(img, f.Color, alpha*f.Opacity, blurAlpha(shifted, f.Blur))
+}
+
+// blurAlpha applies a gaussian blur with the standard deviation sigma.
+func blurAlpha(src *image.Alpha, sigma float64) *image.Alpha {
+	if sigma <= 0 {
+		return src
+	}
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	sum := 0.0
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	tmp := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := 0.0
+			for k, weight := range kernel {
+				if sx := x + k - radius; sx >= 0 && sx < w {
+					v += weight * float64(src.Pix[y*src.Stride+sx])
+				}
+			}
+			tmp[y*w+x] = v
+		}
+	}
+	dst := image.NewAlpha(src.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := 0.0
+			for k, weight := range kernel {
+				if sy := y + k - radius; sy >= 0 && sy < h {
+					v += weight * tmp[sy*w+x]
+				}
+			}
+			dst.Pix[y*dst.Stride+x] = uint8(math.Min(255, v+0.5))
+		}
+	}
+	return dst
+}
+
+// strokeContours returns polygons covering a stroke of the width along the
+// closed contours: a rectangle per edge and a disc per corner for the round
+// joins. All polygons wind the same way, so the nonzero rule merges them.
+func strokeContours(contours [][]Point, width float64) [][]Point {
+	result := [][]Point{}
+	r := width / 2
+	for _, contour := range contours {
+		n := len(contour)
+		for i := 0; i < n; i++ {
+			a, b := contour[i], contour[(i+1)%n]
+			l := distance(a, b)
+			if l == 0 {
+				continue
+			}
+			nx, ny := -(b.Y-a.Y)/l*r, (b.X-a.X)/l*r
+			result = append(result, positive([]Point{
+				{X: a.X + nx, Y: a.Y + ny},
+				{X: b.X + nx, Y: b.Y + ny},
+				{X: b.X - nx, Y: b.Y - ny},
+				{X: a.X - nx, Y: a.Y - ny},
+			}))
+			// joins between nearly collinear edges leave no visible gap
+			c := contour[(i+2)%n]
+			turn := math.Abs(math.Atan2(c.Y-b.Y, c.X-b.X) - math.Atan2(b.Y-a.Y, b.X-a.X))
+			if turn > 0.05 && turn < 2*math.Pi-0.05 {
+				result = append(result, disc(b, r))
+			}
+		}
+	}
+	return result
+}
+
+// disc returns a polygon approximating the circle, winding like positive.
+func disc(center Point, r float64) []Point {
+	n := int(math.Max(8, math.Ceil(2*math.Pi*r/2)))
+	points := make([]Point, n)
+	for i := range points {
+		t := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = Point{X: center.X + r*math.Cos(t), Y: center.Y + r*math.Sin(t)}
+	}
+	return points
+}
+
+// positive returns the polygon with a positive signed area.
+func positive(points []Point) []Point {
+	area := 0.0
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	if area < 0 {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	return points
+}
+
+// PNG writes the rasterized icon to w as a PNG image.
+func (j *jdenticon) PNG(w io.Writer) error {
+	if len(j.svg.p3) > 0 {
+		img, err := rasterizeSVG(j.svg.inDisplayP3())
+		if err != nil {
+			return err
+		}
+		return encodePNG(w, img, true)
+	}
+	img, err := j.rasterize()
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,131 @@
+package jdenticon
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// DefaultMinDifference is a Difference between icons that tells them apart
+// at a glance, for FindDistinct.
+const DefaultMinDifference = 15
+
+// maxSalt bounds the salts tried by FindDistinct.
+const maxSalt = 1000
+
+// differenceCells is the number of cells per edge the icons are averaged
+// into before comparing them, so a pixel offset does not count as different.
+const differenceCells = 16
+
+// ErrNoDistinctSalt is returned by FindDistinct when no salt gives an icon
+// different enough from the existing ones.
+var ErrNoDistinctSalt = errors.New("no distinct salt found") // nolint:gochecknoglobals
+
+// Difference returns the mean CIELAB difference of the icons seen side by
+// side on white, from 0 for identical icons to about 100. The icons must
+// implement Rasterizer.
+func Difference(a, b Jdenticon) (float64, error) {
+	ca, err := iconCells(a)
+	if err != nil {
+		return 0, err
+	}
+	cb, err := iconCells(b)
+	if err != nil {
+		return 0, err
+	}
+	return cellsDifference(ca, cb), nil
+}
+
+// FindDistinct returns the first salt, starting with c.Salt, whose icon of
+// the identity has at least the min Difference from each existing icon. A
+// min of 0 stands for DefaultMinDifference.
+func FindDistinct(identity string, c *Config, existing []Jdenticon, min float64) (int, error) {
+	if min == 0 {
+		min = DefaultMinDifference
+	}
+	cells := make([][]colorful.Color, len(existing))
+	for i, icon := range existing {
+		var err error
+		if cells[i], err = iconCells(icon); err != nil {
+			return 0, err
+		}
+	}
+	cc := *c
+	for salt := c.Salt; salt < c.Salt+maxSalt; salt++ {
+		cc.Salt = salt
+		candidate, err := iconCells(NewWithConfig(identity, &cc))
+		if err != nil {
+			return 0, err
+		}
+		distinct := true
+		for _, other := range cells {
+			if cellsDifference(candidate, other) < min {
+				distinct = false
+				break
+			}
+		}
+		if distinct {
+			return salt, nil
+		}
+	}
+	return 0, ErrNoDistinctSalt
+}
+
+// iconCells renders the icon and averages it into differenceCells² colors
+// composited on white.
+func iconCells(icon Jdenticon) ([]colorful.Color, error) {
+	if j, ok := icon.(*jdenticon); ok {
+		// small icons are enough and much faster to draw
+		icon = j.resize(4*differenceCells, 4*differenceCells)
+	}
+	r, ok := icon.(Rasterizer)
+	if !ok {
+		return nil, errors.New("icon does not implement Rasterizer")
+	}
+	img, err := r.Image()
+	if err != nil {
+		return nil, err
+	}
+	return averageCells(img, differenceCells), nil
+}
+
+func averageCells(img image.Image, n int) []colorful.Color {
+	b := img.Bounds()
+	sums := make([][4]float64, n*n)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		cy := (y - b.Min.Y) * n / b.Dy()
+		for x := b.Min.X; x < b.Max.X; x++ {
+			cx := (x - b.Min.X) * n / b.Dx()
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			a := float64(c.A) / 255
+			s := &sums[cy*n+cx]
+			s[0] += (float64(c.R)/255)*a + 1 - a
+			s[1] += (float64(c.G)/255)*a + 1 - a
+			s[2] += (float64(c.B)/255)*a + 1 - a
+			s[3]++
+		}
+	}
+	cells := make([]colorful.Color, len(sums))
+	for i, s := range sums {
+		if s[3] == 0 {
+			cells[i] = colorful.Color{R: 1, G: 1, B: 1}
+			continue
+		}
+		cells[i] = colorful.Color{R: s[0] / s[3], G: s[1] / s[3], B: s[2] / s[3]}
+	}
+	return cells
+}
+
+func cellsDifference(a, b []colorful.Color) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	sum := 0.0
+	for i := range a {
+		sum += a[i].DistanceLab(b[i])
+	}
+	// colorful scales the lightness to 1 instead of 100
+	return sum / float64(len(a)) * 100
+}
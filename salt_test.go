@@ -0,0 +1,72 @@
+package jdenticon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSaltHash(t *testing.T) {
+	// HMAC-SHA1 keyed by the salt as 8 big-endian bytes
+	tests := []struct {
+		salt int
+		want string
+	}{
+		{0, "522b276a356bdf39013dfabea2cd43e141ecc9e8"},
+		{1, "d3bc1e6445de7970aeb1e7e79bde664cba049a0f"},
+		{2, "d896c65927b058091a4b00e4aa5e005017bbda47"},
+		{42, "6c9e96355307adc0c7dc7e08131b3c8976853aba"},
+		{-1, "6b0f5e3e4b7241f9ecdbec68b1c1513535f0dc7d"},
+	}
+	for _, tt := range tests {
+		if got := (&Config{Salt: tt.salt}).hashIdentity("alice"); got != tt.want {
+			t.Errorf("salt %d: hashIdentity(alice) = %s, want %s", tt.salt, got, tt.want)
+		}
+	}
+}
+
+func TestSaltZero(t *testing.T) {
+	c := *DefaultConfig
+	c.Salt = 0
+	salted, err := NewWithConfig("alice", &c).SVG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsalted, err := newJdenticon(hashIdentity("alice"), &c).SVG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(salted, unsalted) {
+		t.Error("salt 0 changes the icon")
+	}
+}
+
+func TestSaltDistinct(t *testing.T) {
+	seen := map[string]int{hashIdentity("alice"): 0}
+	for salt := 1; salt <= 1000; salt++ {
+		h := (&Config{Salt: salt}).hashIdentity("alice")
+		if other, ok := seen[h]; ok {
+			t.Fatalf("salts %d and %d give the same hash", other, salt)
+		}
+		seen[h] = salt
+	}
+}
+
+func TestFindDistinct(t *testing.T) {
+	existing := []Jdenticon{New("alice")}
+	salt, err := FindDistinct("alice", DefaultConfig, existing, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if salt == 0 {
+		t.Fatal("FindDistinct() returned the salt of an existing icon")
+	}
+	c := *DefaultConfig
+	c.Salt = salt
+	d, err := Difference(existing[0], NewWithConfig("alice", &c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d < DefaultMinDifference {
+		t.Errorf("Difference() = %f, want at least %d", d, DefaultMinDifference)
+	}
+}
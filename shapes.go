@@ -1,13 +1,18 @@
 package jdenticon
 
 import (
+	"math"
 	"strconv"
 )
 
-type shapesGetter func(cell float64, index int) Shapes
+// ShapeFunc draws a shape into a cell of the given size, with the top left
+// corner of the cell at the origin. index is the position of the hash digit
+// that selected the shape.
+type ShapeFunc func(cell float64, index int) Shapes
 
+// InnerShapes are the built-in shapes of the center slots.
 // nolint:gochecknoglobals
-var shapeInner = []shapesGetter{
+var InnerShapes = ShapeSet{
 	func(cell float64, index int) Shapes {
 		k := cell * 0.42
 		return Shapes{newPolygon([]Point{
@@ -138,8 +143,9 @@ var shapeInner = []shapesGetter{
 	},
 }
 
+// OuterShapes are the built-in shapes of the side and corner slots.
 // nolint:gochecknoglobals
-var shapeOuter = []shapesGetter{
+var OuterShapes = ShapeSet{
 	func(cell float64, index int) Shapes {
 		return Shapes{newTriangle(0, 0, cell, cell, 0, false)}
 	},
@@ -155,34 +161,74 @@ var shapeOuter = []shapesGetter{
 	},
 }
 
-func (j *jdenticon) renderShapes(getters []shapesGetter, index int, rotationIndex int, positions [][2]float64) Shapes {
-	r := 0
-	if rotationIndex > 0 {
-		h, _ := strconv.ParseInt("0x"+j.hash[rotationIndex:rotationIndex+1], 0, 64)
-		r = int(h)
+// shape returns the index in the set of the shape selected by the hash digit
+// at index.
+func (j *jdenticon) shape(set ShapeSet, index int) int {
+	if j.uniform() {
+		// pick only takes the value modulo the built-in shapes, the
+		// appended ones are picked by consistent hashing
+		base := set.builtinPrefix()
+		if base == 0 {
+			base = len(set)
+		}
+		return set.pick(j.uniformDigit(index).uniform(base), j.hash, index)
+	}
+	n, _ := strconv.ParseInt("0x"+j.hash[index:index+1], 0, 64)
+	return set.pick(int(n), j.hash, index)
+}
+
+// rotation returns the rotation of the first cell in quarter turns, read from
+// the hash digit at index or 0 when index is 0.
+func (j *jdenticon) rotation(index int) int {
+	if index == 0 {
+		return 0
 	}
-	shapeIdx, _ := strconv.ParseInt("0x"+j.hash[index:index+1], 0, 64)
-	getter := getters[int(shapeIdx)%len(getters)]
-	width := j.geometry.X - j.paddings.X
-	// height := j.geometry.Y - j.paddings.Y
-	cell := width / 4
+	if j.uniform() {
+		return j.uniformDigit(index).uniform(4)
+	}
+	h, _ := strconv.ParseInt("0x"+j.hash[index:index+1], 0, 64)
+	return int(h)
+}
+
+func (j *jdenticon) renderShapes(set ShapeSet, index int, rotationIndex int, positions [][2]int) Shapes {
+	rotation := j.rotation(rotationIndex)
+	getter := set[j.shape(set, index)]
+	size := float64(j.config.layout().Size)
 	result := Shapes{}
-	for i := range positions {
-		shapes := getter(cell, index)
-		for _, shape := range shapes {
-			bottomleft := &Point{
-				X: j.zero.X + positions[i][0]*cell,
-				Y: j.zero.Y + positions[i][1]*cell,
-			}
-			center := &Point{
-				X: bottomleft.X + cell/2,
-				Y: bottomleft.Y + cell/2,
+	sources := j.config.Symmetry.sources(positions, j.config.layout().Size)
+	for _, g := range j.grids {
+		cellX := g.size.X / size
+		cellY := g.size.Y / size
+		cell := math.Min(cellX, cellY)
+		for i := range positions {
+			src := sources[i]
+			shapes := getter(cell, index)
+			for _, shape := range shapes {
+				bottomleft := &Point{
+					X: g.origin.X + float64(positions[i][0])*cellX,
+					Y: g.origin.Y + float64(positions[i][1])*cellY,
+				}
+				center := &Point{
+					X: bottomleft.X + cell/2,
+					Y: bottomleft.Y + cell/2,
+				}
+				shape.Translate(bottomleft.X, bottomleft.Y)
+				// every cell is rotated by another 90 degrees, mirrored cells
+				// take the rotation of their source cell
+				shape.Rotate(float64((rotation+src.index)%4)*90, center)
+				if src.mirrorX {
+					shape.Mirror(AxisVertical, center)
+				}
+				if src.mirrorY {
+					shape.Mirror(AxisHorizontal, center)
+				}
+				if cellX != cellY {
+					// stretch the square cell to the grid cell
+					shape = shape.Scale(cellX/cell, cellY/cell, bottomleft)
+				}
+				result = append(result, shape)
 			}
-			shape.Translate(bottomleft.X, bottomleft.Y)
-			shape.Rotate(float64(r%4)*90, center)
-			result = append(result, shape)
 		}
-		r++
 	}
 	return result
 }
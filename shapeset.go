@@ -0,0 +1,119 @@
+package jdenticon
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// ShapeSet is the list of shapes a slot picks from by the hash.
+//
+// The built-in sets keep the selection of Jdenticon. Shapes appended to them,
+// and the shapes of custom sets, are picked by consistent hashing: adding a
+// shape to the end of a set only moves the identities that now get the new
+// shape, all others keep theirs.
+type ShapeSet []ShapeFunc
+
+// Extend returns a copy of the set with the shapes appended.
+func (s ShapeSet) Extend(shapes ...ShapeFunc) ShapeSet {
+	result := make(ShapeSet, 0, len(s)+len(shapes))
+	result = append(result, s...)
+	return append(result, shapes...)
+}
+
+// Validate checks that every shape of the set stays inside a cell of the
+// given size for all hash digit positions a slot can use. The built-in shapes
+// a set starts with are not checked: they are drawn as in Jdenticon, whose
+// large circle spans the four center cells for digit 0, which Layout.Validate
+// rejects.
+func (s ShapeSet) Validate(cell float64) error {
+	if len(s) == 0 {
+		return fmt.Errorf("empty shape set")
+	}
+	const epsilon = 1e-9
+	for i := s.builtinPrefix(); i < len(s); i++ {
+		fn := s[i]
+		for index := 0; index < hashDigits; index++ {
+			for _, shape := range fn(cell, index) {
+				b := shape.Bounds()
+				if b.Min.X < -epsilon || b.Min.Y < -epsilon || b.Max.X > cell+epsilon || b.Max.Y > cell+epsilon {
+					return fmt.Errorf("shape %d exceeds the cell bounds for digit %d", i, index)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// pick returns the index of the shape for the hash digit value n of the slot
+// at the given digit position.
+func (s ShapeSet) pick(n int, hash string, index int) int {
+	base := s.builtinPrefix()
+	if base == len(s) {
+		return n % len(s)
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(hash))
+	_, _ = h.Write([]byte{byte(index)})
+	key := h.Sum64()
+
+	idx := 0
+	if base > 0 {
+		idx = n % base
+	} else {
+		base = 1
+	}
+	// each added shape takes over 1/(size) of the identities
+	for size := base + 1; size <= len(s); size++ {
+		if float64(mix64(key^uint64(size)))/math.MaxUint64 < 1/float64(size) {
+			idx = size - 1
+		}
+	}
+	return idx
+}
+
+// builtinPrefix returns the length of the built-in set the set starts with,
+// or 0 when it doesn't start with one.
+func (s ShapeSet) builtinPrefix() int {
+	for _, builtin := range []ShapeSet{InnerShapes, OuterShapes} {
+		if len(s) < len(builtin) {
+			continue
+		}
+		same := true
+		for i := range builtin {
+			if reflect.ValueOf(s[i]).Pointer() != reflect.ValueOf(builtin[i]).Pointer() {
+				same = false
+				break
+			}
+		}
+		if same {
+			return len(builtin)
+		}
+	}
+	return 0
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (c *Config) inner() ShapeSet {
+	if len(c.Inner) == 0 {
+		return InnerShapes
+	}
+	return c.Inner
+}
+
+func (c *Config) outer() ShapeSet {
+	if len(c.Outer) == 0 {
+		return OuterShapes
+	}
+	return c.Outer
+}
@@ -0,0 +1,66 @@
+package jdenticon
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestShapeSetValidate(t *testing.T) {
+	// a shape leaving the cell only for digit 0 must be reported
+	digitZero := ShapeSet{func(cell float64, index int) Shapes {
+		if index == 0 {
+			return Shapes{newRectangle(0, 0, 2*cell, cell, false)}
+		}
+		return Shapes{newRectangle(0, 0, cell, cell, false)}
+	}}
+	for _, set := range []ShapeSet{digitZero, InnerShapes.Extend(digitZero...)} {
+		if err := set.Validate(10); err == nil {
+			t.Error("Validate() accepted a shape exceeding the cell for digit 0")
+		}
+	}
+	for _, set := range []ShapeSet{InnerShapes, OuterShapes} {
+		if err := set.Validate(10); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// TestShapeSetStability checks that appending a shape only moves the
+// identities that get the new shape, and about 1/len of them.
+func TestShapeSetStability(t *testing.T) {
+	extra := func(cell float64, index int) Shapes {
+		return Shapes{newRectangle(0, 0, cell, cell, false)}
+	}
+	custom := ShapeSet{extra, extra, extra}
+	const identities = 4000
+	for _, algorithm := range []Algorithm{AlgorithmClassic, AlgorithmUniform} {
+		for name, set := range map[string]ShapeSet{"outer": OuterShapes, "custom": custom} {
+			extended := set.Extend(extra)
+			before := &Config{Outer: set, Algorithm: algorithm}
+			after := &Config{Outer: extended, Algorithm: algorithm}
+			moved := 0
+			for i := 0; i < identities; i++ {
+				identity := strconv.Itoa(i)
+				a, b := Fingerprint(identity, before), Fingerprint(identity, after)
+				for k := range a.Slots {
+					if a.Slots[k] == b.Slots[k] {
+						continue
+					}
+					if b.Slots[k].Shape != len(set) {
+						t.Fatalf("algorithm %d %s: identity %s slot %s moved to shape %d, not to the new one",
+							algorithm, name, identity, a.Slots[k].Slot, b.Slots[k].Shape)
+					}
+					moved++
+				}
+				if a.Hue != b.Hue {
+					t.Fatalf("algorithm %d %s: identity %s changed its hue", algorithm, name, identity)
+				}
+			}
+			// two outer slots, each moves with probability 1/len(extended)
+			want := 2 * identities / float64(len(extended))
+			if got := float64(moved); got < 0.8*want || got > 1.2*want {
+				t.Errorf("algorithm %d %s: %d slots moved, want about %.0f", algorithm, name, moved, want)
+			}
+		}
+	}
+}
@@ -3,8 +3,11 @@ package jdenticon
 import (
 	"encoding/xml"
 	"fmt"
+	"html/template"
 	"math"
 	"strings"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
 )
 
 type SVG struct {
@@ -15,6 +18,30 @@ type SVG struct {
 	ViewBox             string   `xml:"viewBox,attr"`
 	Namespace           string   `xml:"xmlns,attr"`
 	Paths               `xml:"path"`
+	Texts               []Text `xml:"text"`
+	// Clip is the visible area when not empty, referenced by ClipID.
+	Clip   Shapes `xml:"-"`
+	ClipID string `xml:"-"`
+	// Border is drawn above the texts, Filters are referenced by the paths.
+	Border    Paths          `xml:"-"`
+	Filters   []Filter       `xml:"-"`
+	Gradients []GradientFill `xml:"-"`
+
+	// Display P3 colors of a wide gamut theme by their sRGB fallback
+	p3 map[string]colorful.Color
+}
+
+// outlines returns the paths followed by the outlines of the texts and the
+// border, for the backends that can't draw text.
+func (s *SVG) outlines() Paths {
+	if len(s.Texts) == 0 && len(s.Border) == 0 {
+		return s.Paths
+	}
+	result := append(Paths{}, s.Paths...)
+	for _, t := range s.Texts {
+		result = append(result, t.outline)
+	}
+	return append(result, s.Border...)
 }
 
 // -----------------------------------------------------------------------------
@@ -30,6 +57,33 @@ type Path struct {
 	Opacity    float64  `xml:"opacity,attr,omitempty"`
 	Stroke     string   `xml:"stroke,attr,omitempty"`
 	UseOpacity bool     `xml:"-"`
+	// StrokeWidth of the stroke, drawn with round joins.
+	StrokeWidth float64 `xml:"stroke-width,attr,omitempty"`
+	// Filter is the ID of the drop shadow cast by the path.
+	Filter string `xml:"-"`
+	// FillP3 and StrokeP3 are the CSS colors of a wide gamut theme.
+	FillP3   string `xml:"-"`
+	StrokeP3 string `xml:"-"`
+	// Gradient is the ID of the gradient replacing Fill in SVG and raster
+	// images.
+	Gradient string `xml:"-"`
+}
+
+// Style returns the CSS of the wide gamut colors, which override the sRGB
+// fallbacks in browsers supporting them.
+func (p Path) Style() template.CSS {
+	return cssColors(p.FillP3, p.StrokeP3)
+}
+
+func cssColors(fill, stroke string) template.CSS {
+	var css []string
+	if fill != "" {
+		css = append(css, "fill: "+fill)
+	}
+	if stroke != "" {
+		css = append(css, "stroke: "+stroke)
+	}
+	return template.CSS(strings.Join(css, "; ")) // nolint:gosec
 }
 
 func (p Path) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
@@ -42,6 +96,9 @@ func (p Path) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	if len(p.Stroke) > 0 {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "stroke"}, Value: p.Stroke})
 	}
+	if p.StrokeWidth > 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "stroke-width"}, Value: fmt.Sprintf("%g", p.StrokeWidth)})
+	}
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "d"}, Value: p.Shapes.String()})
 	if p.UseOpacity {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "opacity"}, Value: fmt.Sprintf("%f", p.Opacity)})
@@ -59,6 +116,31 @@ func (p Path) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 // -----------------------------------------------------------------------------
 
+// Text is a line of text centered at X with its baseline at Y.
+type Text struct {
+	X          float64
+	Y          float64
+	Content    string
+	FontFamily string
+	FontWeight int
+	FontSize   float64
+	Fill       string
+	// FillP3 is the CSS color of a wide gamut theme.
+	FillP3 string
+
+	// the text drawn with the bundled font
+	outline Path
+}
+
+// -----------------------------------------------------------------------------
+
+// Style returns the CSS of the wide gamut color.
+func (t Text) Style() template.CSS {
+	return cssColors(t.FillP3, "")
+}
+
+// -----------------------------------------------------------------------------
+
 type Shapes []Shape
 
 func (shapes Shapes) String() string {
@@ -71,13 +153,39 @@ func (shapes Shapes) String() string {
 
 // -----------------------------------------------------------------------------
 
+// Shape is a closed outline. Rotate, Translate and Mirror modify the shape in
+// place. Scale and Transform return the transformed shape, which is the
+// receiver itself unless its type can't represent the result, like a Circle
+// scaled non-uniformly becoming an Ellipse.
 type Shape interface {
 	Path() string
 	Rotate(deg float64, center *Point)
 	Translate(dx, dy float64)
+	Scale(sx, sy float64, origin *Point) Shape
+	Mirror(axis Axis, origin *Point)
+	Transform(m Matrix) Shape
+	Bounds() Rect
 	Copy() Shape
 }
 
+// Transform applies m to all shapes.
+func (shapes Shapes) Transform(m Matrix) Shapes {
+	result := make(Shapes, 0, len(shapes))
+	for _, shape := range shapes {
+		result = append(result, shape.Transform(m))
+	}
+	return result
+}
+
+// Bounds returns the bounding box of all shapes.
+func (shapes Shapes) Bounds() Rect {
+	r := emptyRect()
+	for _, shape := range shapes {
+		r = r.Union(shape.Bounds())
+	}
+	return r
+}
+
 // -----------------------------------------------------------------------------
 
 type Point struct {
@@ -94,18 +202,42 @@ func (p *Point) Translate(dx, dy float64) {
 	p.Y += dy
 }
 
+func (p *Point) Rotate(deg float64, center *Point) {
+	p.Transform(RotationMatrix(deg, center))
+}
+
+func (p *Point) Scale(sx, sy float64, origin *Point) {
+	p.Transform(ScaleMatrix(sx, sy, origin))
+}
+
+func (p *Point) Mirror(axis Axis, origin *Point) {
+	p.Transform(MirrorMatrix(axis, origin))
+}
+
+func (p *Point) Transform(m Matrix) {
+	*p = m.Apply(*p)
+}
+
+func (p *Point) Bounds() Rect {
+	return Rect{Min: *p, Max: *p}
+}
+
 // -----------------------------------------------------------------------------
 
 type Circle struct {
 	Center    Point
 	Radius    float64
 	Clockwise bool
+
+	// mirrored circles are drawn in the opposite direction, so they keep
+	// cutting holes into mirrored polygons
+	mirrored bool
 }
 
 func (c *Circle) Path() string {
 	var path string
-	arc1 := fmt.Sprintf("a%.1f,%.1f 0 1,1 %.1f,0", c.Radius, c.Radius, c.Radius*2)
-	arc2 := fmt.Sprintf("a%.1f,%.1f 0 1,1 -%.1f,0", c.Radius, c.Radius, c.Radius*2)
+	arc1 := fmt.Sprintf("a%.1f,%.1f 0 1,%d %.1f,0", c.Radius, c.Radius, sweep(c.mirrored), c.Radius*2)
+	arc2 := fmt.Sprintf("a%.1f,%.1f 0 1,%d -%.1f,0", c.Radius, c.Radius, sweep(c.mirrored), c.Radius*2)
 	if !c.Clockwise {
 		position := fmt.Sprintf("M%.f,%.f", c.Center.X-c.Radius, c.Center.Y)
 		path = position + arc1 + arc2
@@ -130,6 +262,41 @@ func (c *Circle) Rotate(deg float64, center *Point) {
 	c.Center.X, c.Center.Y = rotate(deg, c.Center.X, c.Center.Y, x0, y0)
 }
 
+func (c *Circle) Scale(sx, sy float64, origin *Point) Shape {
+	return c.Transform(ScaleMatrix(sx, sy, origin))
+}
+
+func (c *Circle) Mirror(axis Axis, origin *Point) {
+	c.Center.Mirror(axis, origin)
+	c.mirrored = !c.mirrored
+}
+
+func (c *Circle) Transform(m Matrix) Shape {
+	if !m.similarity() {
+		e := &Ellipse{
+			Center:    c.Center,
+			RadiusX:   c.Radius,
+			RadiusY:   c.Radius,
+			Clockwise: c.Clockwise,
+			mirrored:  c.mirrored,
+		}
+		return e.Transform(m)
+	}
+	c.Center.Transform(m)
+	c.Radius *= math.Sqrt(math.Abs(m.Det()))
+	if m.Det() < 0 {
+		c.mirrored = !c.mirrored
+	}
+	return c
+}
+
+func (c *Circle) Bounds() Rect {
+	return Rect{
+		Min: Point{c.Center.X - c.Radius, c.Center.Y - c.Radius},
+		Max: Point{c.Center.X + c.Radius, c.Center.Y + c.Radius},
+	}
+}
+
 func (c *Circle) Copy() Shape {
 	result := &Circle{}
 	*result = *c
@@ -147,6 +314,110 @@ func newCircle(x, y, size float64, cw bool) *Circle {
 	}
 }
 
+// round1 rounds to one decimal without producing negative zeros.
+func round1(v float64) float64 {
+	return math.Round(v*10)/10 + 0
+}
+
+func sweep(mirrored bool) int {
+	if mirrored {
+		return 0
+	}
+	return 1
+}
+
+// -----------------------------------------------------------------------------
+
+// Ellipse is drawn like a Circle with different radii along its axes, which
+// are rotated by Angle degrees.
+type Ellipse struct {
+	Center    Point
+	RadiusX   float64
+	RadiusY   float64
+	Angle     float64
+	Clockwise bool
+
+	mirrored bool
+}
+
+func (e *Ellipse) Path() string {
+	var path string
+	rad := e.Angle * math.Pi / 180
+	dx := e.RadiusX * math.Cos(rad)
+	dy := e.RadiusX * math.Sin(rad)
+	arc1 := fmt.Sprintf("a%.1f,%.1f %.1f 1,%d %.1f,%.1f", e.RadiusX, e.RadiusY, e.Angle, sweep(e.mirrored), round1(2*dx), round1(2*dy))
+	arc2 := fmt.Sprintf("a%.1f,%.1f %.1f 1,%d %.1f,%.1f", e.RadiusX, e.RadiusY, e.Angle, sweep(e.mirrored), round1(-2*dx), round1(-2*dy))
+	if !e.Clockwise {
+		position := fmt.Sprintf("M%.1f,%.1f", round1(e.Center.X-dx), round1(e.Center.Y-dy))
+		path = position + arc1 + arc2
+	} else {
+		position := fmt.Sprintf("M%.1f,%.1f", round1(e.Center.X+dx), round1(e.Center.Y+dy))
+		path = position + arc2 + arc1
+	}
+	return path
+}
+
+func (e *Ellipse) Translate(dx, dy float64) {
+	e.Center.Translate(dx, dy)
+}
+
+func (e *Ellipse) Rotate(deg float64, center *Point) {
+	e.Center.Rotate(deg, center)
+	e.Angle = math.Mod(e.Angle+deg, 360)
+}
+
+func (e *Ellipse) Scale(sx, sy float64, origin *Point) Shape {
+	return e.Transform(ScaleMatrix(sx, sy, origin))
+}
+
+func (e *Ellipse) Mirror(axis Axis, origin *Point) {
+	e.Transform(MirrorMatrix(axis, origin))
+}
+
+// Transform maps the axes of the ellipse with the linear part of m and
+// decomposes the result into rotation and radii again.
+func (e *Ellipse) Transform(m Matrix) Shape {
+	rad := e.Angle * math.Pi / 180
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	// columns of the matrix mapping the unit circle to the transformed ellipse
+	a := m.A*e.RadiusX*cos + m.C*e.RadiusX*sin
+	c := m.B*e.RadiusX*cos + m.D*e.RadiusX*sin
+	b := -m.A*e.RadiusY*sin + m.C*e.RadiusY*cos
+	d := -m.B*e.RadiusY*sin + m.D*e.RadiusY*cos
+
+	p, q := (a+d)/2, (a-d)/2
+	r, t := (c+b)/2, (c-b)/2
+	s1 := math.Hypot(p, t)
+	s2 := math.Hypot(q, r)
+	angle := (math.Atan2(t, p) + math.Atan2(r, q)) / 2
+
+	e.Center.Transform(m)
+	e.RadiusX = s1 + s2
+	e.RadiusY = math.Abs(s1 - s2)
+	e.Angle = angle * 180 / math.Pi
+	if m.Det() < 0 {
+		e.mirrored = !e.mirrored
+	}
+	return e
+}
+
+func (e *Ellipse) Bounds() Rect {
+	rad := e.Angle * math.Pi / 180
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	hx := math.Hypot(e.RadiusX*cos, e.RadiusY*sin)
+	hy := math.Hypot(e.RadiusX*sin, e.RadiusY*cos)
+	return Rect{
+		Min: Point{e.Center.X - hx, e.Center.Y - hy},
+		Max: Point{e.Center.X + hx, e.Center.Y + hy},
+	}
+}
+
+func (e *Ellipse) Copy() Shape {
+	result := &Ellipse{}
+	*result = *e
+	return result
+}
+
 // -----------------------------------------------------------------------------
 
 type Polygon struct {
@@ -190,9 +461,33 @@ func (s *Polygon) Rotate(deg float64, center *Point) {
 	}
 }
 
+func (s *Polygon) Scale(sx, sy float64, origin *Point) Shape {
+	return s.Transform(ScaleMatrix(sx, sy, origin))
+}
+
+func (s *Polygon) Mirror(axis Axis, origin *Point) {
+	s.Transform(MirrorMatrix(axis, origin))
+}
+
+func (s *Polygon) Transform(m Matrix) Shape {
+	for idx := range s.Points {
+		s.Points[idx].Transform(m)
+	}
+	return s
+}
+
+func (s *Polygon) Bounds() Rect {
+	r := emptyRect()
+	for idx := range s.Points {
+		r = r.Union(s.Points[idx].Bounds())
+	}
+	return r
+}
+
 func (s *Polygon) Copy() Shape {
 	result := &Polygon{}
 	*result = *s
+	result.Points = append([]Point(nil), s.Points...)
 	return result
 }
 
@@ -252,3 +547,130 @@ func rotate(deg float64, x, y, x0, y0 float64) (float64, float64) {
 	yn := y0 + (y-y0)*math.Cos(rad) + (x-x0)*math.Sin(rad)
 	return xn, yn
 }
+
+// -----------------------------------------------------------------------------
+
+// Rect is an axis-aligned rectangle.
+type Rect struct {
+	Min Point
+	Max Point
+}
+
+// emptyRect is the identity of Union.
+func emptyRect() Rect {
+	return Rect{
+		Min: Point{math.Inf(1), math.Inf(1)},
+		Max: Point{math.Inf(-1), math.Inf(-1)},
+	}
+}
+
+func (r Rect) Width() float64 {
+	return r.Max.X - r.Min.X
+}
+
+func (r Rect) Height() float64 {
+	return r.Max.Y - r.Min.Y
+}
+
+// Empty reports whether the rectangle contains no points.
+func (r Rect) Empty() bool {
+	return r.Min.X > r.Max.X || r.Min.Y > r.Max.Y
+}
+
+// Union returns the smallest rectangle containing both rectangles.
+func (r Rect) Union(o Rect) Rect {
+	return Rect{
+		Min: Point{math.Min(r.Min.X, o.Min.X), math.Min(r.Min.Y, o.Min.Y)},
+		Max: Point{math.Max(r.Max.X, o.Max.X), math.Max(r.Max.Y, o.Max.Y)},
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Axis is the direction of a mirror axis.
+type Axis int
+
+const (
+	// AxisVertical mirrors left and right.
+	AxisVertical Axis = iota
+	// AxisHorizontal mirrors top and bottom.
+	AxisHorizontal
+)
+
+// Matrix is an affine transformation in the SVG notation, mapping (x, y) to
+// (A*x + C*y + E, B*x + D*y + F).
+type Matrix struct {
+	A, B, C, D, E, F float64
+}
+
+// IdentityMatrix leaves points unchanged.
+func IdentityMatrix() Matrix {
+	return Matrix{A: 1, D: 1}
+}
+
+func TranslationMatrix(dx, dy float64) Matrix {
+	return Matrix{A: 1, D: 1, E: dx, F: dy}
+}
+
+// RotationMatrix rotates clockwise on screen by deg degrees around center,
+// or around the origin when center is nil.
+func RotationMatrix(deg float64, center *Point) Matrix {
+	rad := deg * math.Pi / 180
+	sin, cos := math.Sin(rad), math.Cos(rad)
+	return around(Matrix{A: cos, B: sin, C: -sin, D: cos}, center)
+}
+
+// ScaleMatrix scales by sx and sy relative to origin, or to (0, 0) when
+// origin is nil.
+func ScaleMatrix(sx, sy float64, origin *Point) Matrix {
+	return around(Matrix{A: sx, D: sy}, origin)
+}
+
+// MirrorMatrix mirrors along the axis through origin, or through (0, 0) when
+// origin is nil.
+func MirrorMatrix(axis Axis, origin *Point) Matrix {
+	if axis == AxisHorizontal {
+		return around(Matrix{A: 1, D: -1}, origin)
+	}
+	return around(Matrix{A: -1, D: 1}, origin)
+}
+
+func around(m Matrix, origin *Point) Matrix {
+	if origin == nil {
+		return m
+	}
+	return TranslationMatrix(-origin.X, -origin.Y).Then(m).Then(TranslationMatrix(origin.X, origin.Y))
+}
+
+// Then returns the transformation applying m first and n second.
+func (m Matrix) Then(n Matrix) Matrix {
+	return Matrix{
+		A: n.A*m.A + n.C*m.B,
+		B: n.B*m.A + n.D*m.B,
+		C: n.A*m.C + n.C*m.D,
+		D: n.B*m.C + n.D*m.D,
+		E: n.A*m.E + n.C*m.F + n.E,
+		F: n.B*m.E + n.D*m.F + n.F,
+	}
+}
+
+func (m Matrix) Apply(p Point) Point {
+	return Point{
+		X: m.A*p.X + m.C*p.Y + m.E,
+		Y: m.B*p.X + m.D*p.Y + m.F,
+	}
+}
+
+// Det returns the determinant of the linear part, negative for mirroring
+// transformations.
+func (m Matrix) Det() float64 {
+	return m.A*m.D - m.B*m.C
+}
+
+// similarity reports whether m keeps angles, so circles stay circles.
+func (m Matrix) similarity() bool {
+	const epsilon = 1e-9
+	rotation := math.Abs(m.A-m.D) < epsilon && math.Abs(m.B+m.C) < epsilon
+	reflection := math.Abs(m.A+m.D) < epsilon && math.Abs(m.B-m.C) < epsilon
+	return rotation || reflection
+}
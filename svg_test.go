@@ -0,0 +1,116 @@
+package jdenticon
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEllipsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		ellipse Ellipse
+		want    string
+	}{
+		{
+			name:    "counterclockwise",
+			ellipse: Ellipse{Center: Point{X: 10.25, Y: 20.04}, RadiusX: 4, RadiusY: 2},
+			want:    "M6.3,20.0a4.0,2.0 0.0 1,1 8.0,0.0a4.0,2.0 0.0 1,1 -8.0,0.0",
+		},
+		{
+			name:    "clockwise",
+			ellipse: Ellipse{Center: Point{X: 10.25, Y: 20.04}, RadiusX: 4, RadiusY: 2, Angle: 90, Clockwise: true},
+			want:    "M10.3,24.0a4.0,2.0 90.0 1,1 0.0,-8.0a4.0,2.0 90.0 1,1 0.0,8.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ellipse.Path(); got != tt.want {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEllipseTransform(t *testing.T) {
+	tests := []struct {
+		name     string
+		ellipse  Ellipse
+		m        Matrix
+		want     Ellipse
+		mirrored bool
+	}{
+		{
+			name:    "rotation",
+			ellipse: Ellipse{Center: Point{X: 10, Y: 20}, RadiusX: 4, RadiusY: 2},
+			m:       RotationMatrix(90, nil),
+			want:    Ellipse{Center: Point{X: -20, Y: 10}, RadiusX: 4, RadiusY: 2, Angle: 90},
+		},
+		{
+			name:    "scale circle",
+			ellipse: Ellipse{Center: Point{X: 10, Y: 20}, RadiusX: 3, RadiusY: 3},
+			m:       ScaleMatrix(2, 1, nil),
+			want:    Ellipse{Center: Point{X: 20, Y: 20}, RadiusX: 6, RadiusY: 3},
+		},
+		{
+			name:    "scale rotated",
+			ellipse: Ellipse{Center: Point{X: 10, Y: 20}, RadiusX: 4, RadiusY: 2, Angle: 90},
+			m:       ScaleMatrix(1, 3, &Point{X: 10, Y: 20}),
+			want:    Ellipse{Center: Point{X: 10, Y: 20}, RadiusX: 12, RadiusY: 2, Angle: 90},
+		},
+		{
+			name:    "scale skewed",
+			ellipse: Ellipse{Center: Point{X: 0, Y: 0}, RadiusX: 2, RadiusY: 2, Angle: 45},
+			m:       ScaleMatrix(3, 1, nil),
+			want:    Ellipse{RadiusX: 6, RadiusY: 2},
+		},
+		{
+			name:     "mirror",
+			ellipse:  Ellipse{Center: Point{X: 10, Y: 20}, RadiusX: 4, RadiusY: 2, Angle: 30},
+			m:        MirrorMatrix(AxisVertical, nil),
+			want:     Ellipse{Center: Point{X: -10, Y: 20}, RadiusX: 4, RadiusY: 2, Angle: -30},
+			mirrored: true,
+		},
+	}
+	const eps = 1e-9
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.ellipse
+			got := tt.ellipse.Copy().(*Ellipse)
+			got.Transform(tt.m)
+			if math.Abs(got.Center.X-tt.want.Center.X) > eps || math.Abs(got.Center.Y-tt.want.Center.Y) > eps {
+				t.Errorf("center = %v, want %v", got.Center, tt.want.Center)
+			}
+			if math.Abs(got.RadiusX-tt.want.RadiusX) > eps || math.Abs(got.RadiusY-tt.want.RadiusY) > eps {
+				t.Errorf("radii = %v, %v, want %v, %v", got.RadiusX, got.RadiusY, tt.want.RadiusX, tt.want.RadiusY)
+			}
+			// an ellipse looks the same after half a turn
+			if d := math.Mod(got.Angle-tt.want.Angle+360, 180); d > eps && 180-d > eps {
+				t.Errorf("angle = %v, want %v", got.Angle, tt.want.Angle)
+			}
+			if got.mirrored != tt.mirrored {
+				t.Errorf("mirrored = %v, want %v", got.mirrored, tt.mirrored)
+			}
+			// the transformed points of the original lie on the result
+			rad := got.Angle * math.Pi / 180
+			for i := 0; i < 12; i++ {
+				p := tt.m.Apply(original.point(float64(i) * math.Pi / 6))
+				dx, dy := p.X-got.Center.X, p.Y-got.Center.Y
+				u := (dx*math.Cos(rad) + dy*math.Sin(rad)) / got.RadiusX
+				v := (-dx*math.Sin(rad) + dy*math.Cos(rad)) / got.RadiusY
+				if math.Abs(u*u+v*v-1) > 1e-6 {
+					t.Errorf("point %v is off the ellipse", p)
+				}
+			}
+		})
+	}
+}
+
+// point returns the point of the ellipse at the parameter t in radians.
+func (e Ellipse) point(t float64) Point {
+	rad := e.Angle * math.Pi / 180
+	x, y := e.RadiusX*math.Cos(t), e.RadiusY*math.Sin(t)
+	return Point{
+		X: e.Center.X + x*math.Cos(rad) - y*math.Sin(rad),
+		Y: e.Center.Y + x*math.Sin(rad) + y*math.Cos(rad),
+	}
+}
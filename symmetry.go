@@ -0,0 +1,56 @@
+package jdenticon
+
+// Symmetry selects how the cells of a slot relate to each other. All modes
+// use the same shapes, colors and rotations picked by the hash.
+type Symmetry int
+
+const (
+	// SymmetryRotational rotates every cell of a slot by another 90 degrees,
+	// like Jdenticon does.
+	SymmetryRotational Symmetry = iota
+	// SymmetryHorizontal mirrors the left half of the icon onto the right.
+	SymmetryHorizontal
+	// SymmetryVertical mirrors the top half of the icon onto the bottom.
+	SymmetryVertical
+	// SymmetryBoth mirrors the top left quarter onto the other ones.
+	SymmetryBoth
+)
+
+// cellSource tells which cell of a slot a cell copies, and along which axes
+// the copy is mirrored.
+type cellSource struct {
+	index   int
+	mirrorX bool
+	mirrorY bool
+}
+
+// sources returns the source of every position of a slot in a grid of size
+// cells. Cells whose mirror image isn't part of the slot keep their own
+// rotation.
+func (s Symmetry) sources(positions [][2]int, size int) []cellSource {
+	index := map[[2]int]int{}
+	for i, p := range positions {
+		index[p] = i
+	}
+	mirrorX := s == SymmetryHorizontal || s == SymmetryBoth
+	mirrorY := s == SymmetryVertical || s == SymmetryBoth
+	result := make([]cellSource, len(positions))
+	for i, p := range positions {
+		result[i] = cellSource{index: i}
+		src := p
+		if mirrorX && 2*p[0] > size-1 {
+			src[0] = size - 1 - p[0]
+		}
+		if mirrorY && 2*p[1] > size-1 {
+			src[1] = size - 1 - p[1]
+		}
+		if idx, ok := index[src]; ok && src != p {
+			result[i] = cellSource{
+				index:   idx,
+				mirrorX: src[0] != p[0],
+				mirrorY: src[1] != p[1],
+			}
+		}
+	}
+	return result
+}
@@ -2,12 +2,59 @@ package jdenticon
 
 // nolint:lll
 const tmpl = `<svg width="{{.Width}}" height="{{.Height}}" preserveAspectRatio="xMidYMid meet" viewBox="0 0 {{.Width}} {{.Height}}" xmlns="http://www.w3.org/2000/svg">
-	{{- range .Paths -}}
+	{{- if or .Clip .Filters .Gradients -}}
+		<defs>
+			{{- if .Clip -}}
+				<clipPath id="{{.ClipID}}"><path d="{{.Clip}}"/></clipPath>
+			{{- end -}}
+			{{- range .Gradients -}}
+				{{- if .Radial -}}
+					<radialGradient id="{{.ID}}">
+						<stop offset="0" stop-color="{{.From}}"{{with .FromStyle}} style="{{.}}"{{end}}/><stop offset="1" stop-color="{{.To}}"{{with .ToStyle}} style="{{.}}"{{end}}/>
+					</radialGradient>
+				{{- else -}}
+					<linearGradient id="{{.ID}}" x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}">
+						<stop offset="0" stop-color="{{.From}}"{{with .FromStyle}} style="{{.}}"{{end}}/><stop offset="1" stop-color="{{.To}}"{{with .ToStyle}} style="{{.}}"{{end}}/>
+					</linearGradient>
+				{{- end -}}
+			{{- end -}}
+			{{- range .Filters -}}
+				<filter id="{{.ID}}" x="-50%" y="-50%" width="200%" height="200%">
+					<feDropShadow dx="{{.DX}}" dy="{{.DY}}" stdDeviation="{{.Blur}}" flood-color="{{.Color}}" flood-opacity="{{.Opacity}}"/>
+				</filter>
+			{{- end -}}
+		</defs>
+	{{- end -}}
+	{{- if .Clip -}}
+		<g clip-path="url(#{{.ClipID}})">
+	{{- end -}}
+	{{- template "paths" .Paths -}}
+	{{- range .Texts -}}
+		<text x="{{.X}}" y="{{.Y}}" text-anchor="middle" font-family="{{.FontFamily}}" font-weight="{{.FontWeight}}" font-size="{{.FontSize}}" fill="{{.Fill}}"{{with .Style}} style="{{.}}"{{end}}>
+			{{- .Content -}}
+		</text>
+	{{- end -}}
+	{{- template "paths" .Border -}}
+	{{- if .Clip -}}
+		</g>
+	{{- end -}}
+</svg>`
+
+// nolint:lll
+const patternTmpl = `<pattern id="{{.ID}}" width="{{.SVG.Width}}" height="{{.SVG.Height}}" patternUnits="userSpaceOnUse">
+	{{- template "paths" .SVG.Paths -}}
+</pattern>`
+
+const pathsTmpl = `{{define "paths"}}
+	{{- range . -}}
 		<path 
-			{{- if .Fill}} fill="{{.Fill}}"{{end -}}
+			{{- if .Gradient}} fill="url(#{{.Gradient}})"{{else if .Fill}} fill="{{.Fill}}"{{end -}}
 			{{- if .Stroke}} stroke="{{.Stroke}}"{{end -}}
+			{{- if .StrokeWidth}} stroke-width="{{.StrokeWidth}}" stroke-linejoin="round"{{end -}}
+			{{- if .Filter}} filter="url(#{{.Filter}})"{{end -}}
+			{{- with .Style}} style="{{.}}"{{end -}}
 			{{- if .UseOpacity}} opacity="{{.Opacity}}"{{end -}}
 			{{- if .Shapes}} d="{{.Shapes}}"{{end -}}
 		/>
 	{{- end -}}
-</svg>`
+{{end}}`
@@ -0,0 +1,182 @@
+package jdenticon
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"strings"
+)
+
+// TerminalMode selects the escape sequences used by Terminal.
+type TerminalMode int
+
+const (
+	// TrueColor uses 24-bit ANSI colors.
+	TrueColor TerminalMode = iota
+	// Color256 uses the xterm 256 color palette.
+	Color256
+	// ASCII prints plain characters without any escape sequences.
+	ASCII
+)
+
+// characters of increasing density used by the ASCII mode
+const asciiRamp = ".:-=+*#%@"
+
+// TerminalModeFromEnv guesses the best mode supported by the terminal from
+// the COLORTERM and TERM environment variables.
+func TerminalModeFromEnv() TerminalMode {
+	return terminalMode(os.Getenv("COLORTERM"), os.Getenv("TERM"))
+}
+
+func terminalMode(colorterm, term string) TerminalMode {
+	if colorterm == "truecolor" || colorterm == "24bit" {
+		return TrueColor
+	}
+	switch {
+	case term == "" || term == "dumb":
+		return ASCII
+	case strings.Contains(term, "256color"):
+		return Color256
+	case strings.Contains(term, "direct"):
+		return TrueColor
+	}
+	return Color256
+}
+
+// Terminal prints the icon rasterized at size pixels. Each character cell
+// shows two pixels on top of each other using the upper half block "▀", and
+// transparent pixels are left in the terminal's default background.
+func (j *jdenticon) Terminal(w io.Writer, size int, mode TerminalMode) error {
+	img, err := j.resize(size, size).rasterize()
+	if err != nil {
+		return err
+	}
+	return encodeTerminal(w, img, mode)
+}
+
+func encodeTerminal(w io.Writer, img image.Image, mode TerminalMode) error {
+	out := bufio.NewWriter(w)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top := img.At(x, y)
+			bottom := color.Color(color.Transparent)
+			if y+1 < b.Max.Y {
+				bottom = img.At(x, y+1)
+			}
+			if mode == ASCII {
+				_ = out.WriteByte(asciiCell(top, bottom))
+			} else {
+				_, _ = out.WriteString(halfBlock(top, bottom, mode))
+			}
+		}
+		if mode != ASCII {
+			_, _ = out.WriteString("\x1b[0m")
+		}
+		_ = out.WriteByte('\n')
+	}
+	return out.Flush()
+}
+
+// visible reports whether the pixel is opaque enough to be drawn, and its
+// color without premultiplied alpha.
+func visible(c color.Color) (color.NRGBA, bool) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n, n.A >= 0x80
+}
+
+func halfBlock(top, bottom color.Color, mode TerminalMode) string {
+	t, hasTop := visible(top)
+	b, hasBottom := visible(bottom)
+	switch {
+	case hasTop && hasBottom:
+		return ansiColor(t, mode, true) + ansiColor(b, mode, false) + "▀"
+	case hasTop:
+		return ansiColor(t, mode, true) + "\x1b[49m▀"
+	case hasBottom:
+		return ansiColor(b, mode, true) + "\x1b[49m▄"
+	}
+	return "\x1b[0m "
+}
+
+func ansiColor(c color.NRGBA, mode TerminalMode, foreground bool) string {
+	layer := 48
+	if foreground {
+		layer = 38
+	}
+	if mode == Color256 {
+		return fmt.Sprintf("\x1b[%d;5;%dm", layer, xterm256(c))
+	}
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, c.R, c.G, c.B)
+}
+
+// xterm256 returns the closest color of the 6x6x6 cube or the grayscale ramp
+// of the xterm palette.
+func xterm256(c color.NRGBA) int {
+	levels := [6]int{0, 95, 135, 175, 215, 255}
+	nearest := func(v uint8) int {
+		best := 0
+		for i, l := range levels {
+			if abs(int(v)-l) < abs(int(v)-levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	r, g, b := nearest(c.R), nearest(c.G), nearest(c.B)
+	cube := 16 + 36*r + 6*g + b
+	cubeDist := sqDist(c, levels[r], levels[g], levels[b])
+
+	avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+	gray := (avg - 8 + 5) / 10
+	if gray < 0 {
+		gray = 0
+	} else if gray > 23 {
+		gray = 23
+	}
+	v := 8 + 10*gray
+	if sqDist(c, v, v, v) < cubeDist {
+		return 232 + gray
+	}
+	return cube
+}
+
+func sqDist(c color.NRGBA, r, g, b int) int {
+	dr, dg, db := int(c.R)-r, int(c.G)-g, int(c.B)-b
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// asciiCell picks a character whose density follows the darkness of the two
+// pixels it stands for.
+func asciiCell(top, bottom color.Color) byte {
+	var (
+		darkness float64
+		count    int
+	)
+	for _, c := range []color.Color{top, bottom} {
+		if n, ok := visible(c); ok {
+			y := color.GrayModel.Convert(n).(color.Gray).Y
+			darkness += 1 - float64(y)/255
+			count++
+		}
+	}
+	if count == 0 {
+		return ' '
+	}
+	// a transparent half counts as white, so half covered cells are lighter
+	idx := int(darkness / 2 * float64(len(asciiRamp)))
+	if idx >= len(asciiRamp) {
+		idx = len(asciiRamp) - 1
+	}
+	return asciiRamp[idx]
+}
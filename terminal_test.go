@@ -0,0 +1,104 @@
+package jdenticon
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+)
+
+func TestEncodeTerminal(t *testing.T) {
+	// red over blue, green below a transparent pixel, then a white pixel in
+	// an odd last row
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 3))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(0, 1, color.NRGBA{B: 255, A: 255})
+	img.SetNRGBA(1, 1, color.NRGBA{G: 255, A: 255})
+	img.SetNRGBA(1, 2, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	tests := []struct {
+		name string
+		mode TerminalMode
+		want string
+	}{
+		{
+			name: "truecolor",
+			mode: TrueColor,
+			want: "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀" +
+				"\x1b[38;2;0;255;0m\x1b[49m▄" +
+				"\x1b[0m\n" +
+				"\x1b[0m " +
+				"\x1b[38;2;255;255;255m\x1b[49m▀" +
+				"\x1b[0m\n",
+		},
+		{
+			name: "256",
+			mode: Color256,
+			want: "\x1b[38;5;196m\x1b[48;5;21m▀" +
+				"\x1b[38;5;46m\x1b[49m▄" +
+				"\x1b[0m\n" +
+				"\x1b[0m " +
+				"\x1b[38;5;231m\x1b[49m▀" +
+				"\x1b[0m\n",
+		},
+		{
+			name: "ascii",
+			mode: ASCII,
+			want: "%:\n .\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeTerminal(&buf, img, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("encodeTerminal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminalMode(t *testing.T) {
+	tests := []struct {
+		colorterm string
+		term      string
+		want      TerminalMode
+	}{
+		{"truecolor", "xterm", TrueColor},
+		{"24bit", "", TrueColor},
+		{"", "xterm-direct", TrueColor},
+		{"", "xterm-256color", Color256},
+		{"", "screen-256color", Color256},
+		{"", "xterm", Color256},
+		{"", "dumb", ASCII},
+		{"", "", ASCII},
+		{"yes", "dumb", ASCII},
+	}
+	for _, tt := range tests {
+		if got := terminalMode(tt.colorterm, tt.term); got != tt.want {
+			t.Errorf("terminalMode(%q, %q) = %d, want %d", tt.colorterm, tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalModeFromEnv(t *testing.T) {
+	for _, name := range []string{"COLORTERM", "TERM"} {
+		if old, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, old) // nolint:errcheck
+		} else {
+			defer os.Unsetenv(name) // nolint:errcheck
+		}
+	}
+	_ = os.Setenv("COLORTERM", "truecolor")
+	_ = os.Setenv("TERM", "dumb")
+	if got := TerminalModeFromEnv(); got != TrueColor {
+		t.Errorf("TerminalModeFromEnv() = %d, want TrueColor", got)
+	}
+	_ = os.Unsetenv("COLORTERM")
+	if got := TerminalModeFromEnv(); got != ASCII {
+		t.Errorf("TerminalModeFromEnv() = %d, want ASCII", got)
+	}
+}
@@ -0,0 +1,76 @@
+package jdenticon
+
+import (
+	"encoding/json"
+	"image/color"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// ThemeColor is a color of a theme. It marshals to JSON as its hex string.
+type ThemeColor struct {
+	Color color.Color
+	Hex   string
+}
+
+func newThemeColor(hex string) ThemeColor {
+	c, _ := colorful.Hex(hex)
+	return ThemeColor{Color: c, Hex: hex}
+}
+
+func (c ThemeColor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Hex)
+}
+
+func (c *ThemeColor) UnmarshalJSON(data []byte) error {
+	var hex string
+	if err := json.Unmarshal(data, &hex); err != nil {
+		return err
+	}
+	parsed, err := colorful.Hex(hex)
+	if err != nil {
+		return err
+	}
+	c.Color, c.Hex = parsed, hex
+	return nil
+}
+
+// ThemeColors are the colors of an identicon, to tint a user interface like
+// the icon.
+type ThemeColors struct {
+	DarkGray   ThemeColor `json:"darkGray"`
+	MidColor   ThemeColor `json:"midColor"`
+	LightGray  ThemeColor `json:"lightGray"`
+	LightColor ThemeColor `json:"lightColor"`
+	DarkColor  ThemeColor `json:"darkColor"`
+	// Layers are the three colors of the shape layers, indexed by
+	// Slot.Color, or the single color of blocks.
+	Layers []ThemeColor `json:"layers"`
+	// Accent is the main color of the theme, as used by Initials and blocks.
+	Accent ThemeColor `json:"accent"`
+	// OnAccent is a readable color for text on the accent.
+	OnAccent ThemeColor `json:"onAccent"`
+}
+
+// Theme returns the colors of the identicon of the identity.
+func Theme(identity string, c *Config) *ThemeColors {
+	j := newJdenticon(c.hashIdentity(identity), c)
+	theme := j.theme()
+	t := &ThemeColors{
+		DarkGray:   newThemeColor(theme[0]),
+		MidColor:   newThemeColor(theme[1]),
+		LightGray:  newThemeColor(theme[2]),
+		LightColor: newThemeColor(theme[3]),
+		DarkColor:  newThemeColor(theme[4]),
+		Accent:     newThemeColor(theme[1]),
+		OnAccent:   newThemeColor(textColor(theme[1], theme[0])),
+	}
+	if c.Style == StyleBlocks {
+		t.Layers = []ThemeColor{t.Accent}
+		return t
+	}
+	for _, hex := range j.colors() {
+		t.Layers = append(t.Layers, newThemeColor(hex))
+	}
+	return t
+}
@@ -0,0 +1,91 @@
+package jdenticon
+
+import (
+	"math"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// DefaultTintContrast is the least contrast ratio between a tinted
+// background and the layer colors.
+const DefaultTintContrast = 1.3
+
+// Tint is a background color derived from the hue of the identity, so the
+// icons of a grid stand apart by their background too.
+type Tint struct {
+	// Lightness and Saturation of the background, from 0 to 1.
+	Lightness  float64
+	Saturation float64
+	// MinContrast is the least WCAG contrast ratio against every layer
+	// color, 0 for DefaultTintContrast. The lightness moves as little as
+	// possible to reach it.
+	MinContrast float64
+}
+
+// PaleTint is a light background tint.
+var PaleTint = &Tint{Lightness: 0.94, Saturation: 0.45} // nolint:gochecknoglobals
+
+// DarkTint is a dark background tint.
+var DarkTint = &Tint{Lightness: 0.16, Saturation: 0.35} // nolint:gochecknoglobals
+
+// background returns the fill and opacity of the background.
+func (j *jdenticon) background() (string, float64) {
+	t := j.config.BackgroundTint
+	if t == nil {
+		background := j.config.Variant.background(j.config.Background)
+		return toHex(background), opacity(background)
+	}
+	foreground := j.colors()
+	if j.config.Style == StyleBlocks {
+		foreground = j.theme()[1:2]
+	}
+	min := t.MinContrast
+	if min == 0 {
+		min = DefaultTintContrast
+	}
+	hue := j.hue()
+	best, bestContrast, bestDistance := "", 0.0, math.Inf(1)
+	// the lightness closest to the configured one that keeps the contrast,
+	// or the one with the highest contrast when none does
+	for step := 0; step <= 100; step++ {
+		l := float64(step) / 100
+		fill := correctedHsl(hue, t.Saturation, l)
+		contrast := minContrast(fill, foreground)
+		distance := math.Abs(l - j.config.Variant.lightness(t.Lightness))
+		switch {
+		case contrast >= min && (bestContrast < min || distance < bestDistance):
+		case bestContrast < min && contrast > bestContrast:
+		default:
+			continue
+		}
+		best, bestContrast, bestDistance = fill, contrast, distance
+	}
+	return best, 1
+}
+
+// minContrast returns the lowest contrast ratio between the color and the
+// others.
+func minContrast(hex string, others []string) float64 {
+	c, err := colorful.Hex(hex)
+	if err != nil {
+		return 0
+	}
+	result := math.Inf(1)
+	for _, other := range others {
+		o, err := colorful.Hex(other)
+		if err != nil {
+			continue
+		}
+		result = math.Min(result, contrastRatio(c, o))
+	}
+	return result
+}
+
+// contrastRatio returns the contrast ratio of two colors as defined by WCAG.
+func contrastRatio(a, b colorful.Color) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
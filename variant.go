@@ -0,0 +1,69 @@
+package jdenticon
+
+import (
+	"fmt"
+	"image/color"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// Variant adapts the colors of an icon to the lightness of the surface it is
+// shown on. All variants of an identity share shapes, rotations and hue.
+type Variant int
+
+const (
+	// VariantLight suits light surfaces.
+	VariantLight Variant = iota
+	// VariantDark suits dark surfaces. The lightness of the theme colors, the
+	// background and the tint is inverted, so every layer keeps its contrast
+	// with the surface: dark grays become light grays and so on.
+	VariantDark
+)
+
+// ParseVariant parses "light" or "dark", as used by the prefers-color-scheme
+// media feature.
+func ParseVariant(s string) (Variant, error) {
+	switch s {
+	case "light":
+		return VariantLight, nil
+	case "dark":
+		return VariantDark, nil
+	}
+	return VariantLight, fmt.Errorf("unknown variant %q", s)
+}
+
+func (v Variant) String() string {
+	if v == VariantDark {
+		return "dark"
+	}
+	return "light"
+}
+
+// lightness maps a lightness of the light variant to the variant.
+func (v Variant) lightness(l float64) float64 {
+	if v == VariantDark {
+		return 1 - l
+	}
+	return l
+}
+
+// background maps a background color of the light variant to the variant.
+func (v Variant) background(c color.Color) color.Color {
+	if v != VariantDark {
+		return c
+	}
+	cc, _ := colorful.MakeColor(c)
+	_, _, _, a := c.RGBA()
+	r, g, b := v.color(cc).RGB255()
+	return color.NRGBA{R: r, G: g, B: b, A: uint8(a >> 8)}
+}
+
+// color maps a color of the light variant to the variant by inverting its
+// HSL lightness.
+func (v Variant) color(c colorful.Color) colorful.Color {
+	if v != VariantDark {
+		return c
+	}
+	h, s, l := c.Hsl()
+	return colorful.Hsl(h, s, 1-l)
+}
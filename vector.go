@@ -0,0 +1,188 @@
+package jdenticon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// Both exporters use the SVG path data of every layer, either verbatim or
+// parsed by parsePathData, so they stay identical to the SVG output. Neither
+// format has filters, so shadows are left out.
+
+// VectorDrawable writes the icon as an Android <vector> drawable.
+func (j *jdenticon) VectorDrawable(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	fmt.Fprintf(&buf, `<vector xmlns:android="http://schemas.android.com/apk/res/android"`+
+		` android:width="%ddp" android:height="%ddp" android:viewportWidth="%d" android:viewportHeight="%d">`+"\n",
+		j.svg.Width, j.svg.Height, j.svg.Width, j.svg.Height)
+	indent := "    "
+	if len(j.svg.Clip) > 0 {
+		buf.WriteString(`    <group>` + "\n" + `        <clip-path android:pathData="`)
+		if err := xml.EscapeText(&buf, []byte(j.svg.Clip.String())); err != nil {
+			return err
+		}
+		buf.WriteString(`"/>` + "\n")
+		indent += "    "
+	}
+	for _, p := range j.svg.outlines() {
+		if p.UseOpacity && p.Opacity == 0 {
+			continue
+		}
+		buf.WriteString(indent + `<path`)
+		if p.Fill != "none" {
+			buf.WriteString(` android:fillColor="`)
+			if err := xml.EscapeText(&buf, []byte(p.Fill)); err != nil {
+				return err
+			}
+			buf.WriteString(`"`)
+			if p.UseOpacity {
+				fmt.Fprintf(&buf, ` android:fillAlpha="%s"`, formatFloat(p.Opacity))
+			}
+		}
+		if p.Stroke != "" && p.StrokeWidth > 0 {
+			buf.WriteString(` android:strokeColor="`)
+			if err := xml.EscapeText(&buf, []byte(p.Stroke)); err != nil {
+				return err
+			}
+			fmt.Fprintf(&buf, `" android:strokeWidth="%s" android:strokeLineJoin="round"`, formatFloat(p.StrokeWidth))
+			if p.UseOpacity {
+				fmt.Fprintf(&buf, ` android:strokeAlpha="%s"`, formatFloat(p.Opacity))
+			}
+		}
+		buf.WriteString(` android:fillType="nonZero" android:pathData="`)
+		if err := xml.EscapeText(&buf, []byte(p.Shapes.String())); err != nil {
+			return err
+		}
+		buf.WriteString(`"/>` + "\n")
+	}
+	if len(j.svg.Clip) > 0 {
+		buf.WriteString("    </group>\n")
+	}
+	buf.WriteString("</vector>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// PDF writes the icon as a single page vector PDF, as used for vector images
+// in Xcode asset catalogs.
+func (j *jdenticon) PDF(w io.Writer) error {
+	var (
+		content bytes.Buffer
+		states  []string
+	)
+	// PDF user space has its origin at the bottom left corner
+	fmt.Fprintf(&content, "1 0 0 -1 0 %d cm\n", j.svg.Height)
+	if len(j.svg.Clip) > 0 {
+		segments, err := parsePathData(j.svg.Clip.String())
+		if err != nil {
+			return err
+		}
+		writePDFPath(&content, segments)
+		content.WriteString("W n\n")
+	}
+	for _, p := range j.svg.outlines() {
+		if p.UseOpacity && p.Opacity == 0 {
+			continue
+		}
+		segments, err := parsePathData(p.Shapes.String())
+		if err != nil {
+			return err
+		}
+		content.WriteString("q\n")
+		if p.UseOpacity {
+			name := fmt.Sprintf("GS%d", len(states))
+			states = append(states, fmt.Sprintf("/%s << /Type /ExtGState /ca %s /CA %s >>",
+				name, formatFloat(p.Opacity), formatFloat(p.Opacity)))
+			fmt.Fprintf(&content, "/%s gs\n", name)
+		}
+		op := ""
+		if p.Fill != "none" {
+			fill, err := colorful.Hex(p.Fill)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&content, "%s %s %s rg\n", formatFloat(fill.R), formatFloat(fill.G), formatFloat(fill.B))
+			op = "f"
+		}
+		if p.Stroke != "" && p.StrokeWidth > 0 {
+			stroke, err := colorful.Hex(p.Stroke)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&content, "%s %s %s RG\n%s w\n1 j\n",
+				formatFloat(stroke.R), formatFloat(stroke.G), formatFloat(stroke.B), formatFloat(p.StrokeWidth))
+			op += "S"
+		}
+		if op == "fS" {
+			// fill, then stroke
+			op = "B"
+		}
+		if op == "" {
+			op = "n"
+		}
+		writePDFPath(&content, segments)
+		content.WriteString(op + "\nQ\n")
+	}
+
+	// the EOL before endstream is not part of the stream length
+	stream := strings.TrimSuffix(content.String(), "\n")
+	resources := "<< >>"
+	if len(states) > 0 {
+		resources = "<< /ExtGState << " + strings.Join(states, " ") + " >> >>"
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources %s /Contents 4 0 R >>",
+			j.svg.Width, j.svg.Height, resources),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writePDFPath writes the path construction operators of the segments.
+func writePDFPath(content *bytes.Buffer, segments []segment) {
+	for _, s := range segments {
+		switch s.kind {
+		case segmentMove:
+			fmt.Fprintf(content, "%s %s m\n", formatFloat(s.pts[2].X), formatFloat(s.pts[2].Y))
+		case segmentLine:
+			fmt.Fprintf(content, "%s %s l\n", formatFloat(s.pts[2].X), formatFloat(s.pts[2].Y))
+		case segmentCubic:
+			fmt.Fprintf(content, "%s %s %s %s %s %s c\n",
+				formatFloat(s.pts[0].X), formatFloat(s.pts[0].Y),
+				formatFloat(s.pts[1].X), formatFloat(s.pts[1].Y),
+				formatFloat(s.pts[2].X), formatFloat(s.pts[2].Y))
+		case segmentClose:
+			content.WriteString("h\n")
+		}
+	}
+}
+
+// formatFloat formats v with at most three decimals and no trailing zeros.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
@@ -0,0 +1,88 @@
+package jdenticon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// vectorIcon has a clip, a filled path, a translucent filled and stroked path
+// and a stroked path.
+func vectorIcon() *jdenticon {
+	return &jdenticon{svg: &SVG{
+		Width:  10,
+		Height: 10,
+		Clip:   Shapes{newRectangle(1, 1, 8, 8, false)},
+		Paths: Paths{
+			{Fill: "#ff0000", Shapes: Shapes{newRectangle(0, 0, 10, 10, false)}},
+			{
+				Fill: "#00ff00", Stroke: "#0000ff", StrokeWidth: 2, UseOpacity: true, Opacity: 0.5,
+				Shapes: Shapes{newPolygon([]Point{{2, 2}, {8, 2}, {5, 8}}, false)},
+			},
+			{Fill: "none", Stroke: "#000000", StrokeWidth: 1, Shapes: Shapes{newRectangle(3, 3, 4, 4, false)}},
+		},
+	}}
+}
+
+func TestVectorGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		write  func(w io.Writer) error
+	}{
+		{"testdata/vector.xml", vectorIcon().VectorDrawable},
+		{"testdata/vector.pdf", vectorIcon().PDF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			want, err := ioutil.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s:\n%s", tt.golden, buf.String())
+			}
+		})
+	}
+}
+
+func TestPDFStructure(t *testing.T) {
+	var buf bytes.Buffer
+	if err := vectorIcon().PDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// the length leaves out the EOL before endstream
+	m := regexp.MustCompile(`(?s)/Length (\d+) >>\nstream\n(.*?)\nendstream`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no content stream")
+	}
+	if n, _ := strconv.Atoi(string(m[1])); n != len(m[2]) {
+		t.Errorf("/Length %d, stream has %d bytes", n, len(m[2]))
+	}
+
+	// every xref entry points to its object
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if xref == nil {
+		t.Fatal("no startxref")
+	}
+	start, _ := strconv.Atoi(string(xref[1]))
+	if !bytes.HasPrefix(data[start:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to the xref table", start)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[start:], -1)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if obj := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[offset:], []byte(obj)) {
+			t.Errorf("xref entry %d points to %q", i+1, data[offset:offset+len(obj)])
+		}
+	}
+}
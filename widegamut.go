@@ -0,0 +1,278 @@
+package jdenticon
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"unicode/utf16"
+
+	colorful "github.com/lucasb-eyer/go-colorful"
+)
+
+// Wide gamut themes take the HSL colors as Display P3 coordinates, which are
+// more saturated than the same coordinates in sRGB. The SVG and the other
+// formats keep the colors clipped to sRGB as fallback; SVG images add the P3
+// colors in CSS and PNG images are drawn in P3 with an embedded profile.
+
+// Both color spaces use the sRGB transfer function and the D65 white point,
+// these convert between their linear coordinates.
+// nolint:gochecknoglobals
+var (
+	p3ToSRGBMatrix = [3][3]float64{
+		{1.2249401, -0.2249404, 0},
+		{-0.0420569, 1.0420571, 0},
+		{-0.0196376, -0.0786361, 1.0982735},
+	}
+	srgbToP3Matrix = [3][3]float64{
+		{0.8224621, 0.1775380, 0},
+		{0.0331941, 0.9668058, 0},
+		{0.0170827, 0.0723974, 0.9105199},
+	}
+)
+
+func convertLinear(c colorful.Color, m [3][3]float64) colorful.Color {
+	r, g, b := c.LinearRgb()
+	return colorful.LinearRgb(
+		m[0][0]*r+m[0][1]*g+m[0][2]*b,
+		m[1][0]*r+m[1][1]*g+m[1][2]*b,
+		m[2][0]*r+m[2][1]*g+m[2][2]*b,
+	)
+}
+
+// wideColor registers the Display P3 color and returns its sRGB fallback.
+func (s *SVG) wideColor(p3 colorful.Color) string {
+	fallback := convertLinear(p3, p3ToSRGBMatrix).Clamped().Hex()
+	if s.p3 == nil {
+		s.p3 = map[string]colorful.Color{}
+	}
+	s.p3[fallback] = p3
+	return fallback
+}
+
+// displayP3 returns the P3 color of the fallback, or the same color converted
+// to P3 when it is not from the theme.
+func (s *SVG) displayP3(hex string) (colorful.Color, error) {
+	if p3, ok := s.p3[hex]; ok {
+		return p3, nil
+	}
+	c, err := colorful.Hex(hex)
+	if err != nil {
+		return colorful.Color{}, err
+	}
+	return convertLinear(c, srgbToP3Matrix).Clamped(), nil
+}
+
+func cssDisplayP3(c colorful.Color) string {
+	return fmt.Sprintf("color(display-p3 %.4f %.4f %.4f)", c.R, c.G, c.B)
+}
+
+// withCSSColors returns a copy of the SVG with the P3 colors of the theme
+// added to the paths and texts.
+func (s *SVG) withCSSColors() *SVG {
+	if len(s.p3) == 0 {
+		return s
+	}
+	r := *s
+	css := func(hex string) string {
+		if p3, ok := s.p3[hex]; ok {
+			return cssDisplayP3(p3)
+		}
+		return ""
+	}
+	mapPaths := func(paths Paths) Paths {
+		result := make(Paths, len(paths))
+		for i, p := range paths {
+			// a CSS fill would override the gradient, its stops get the P3
+			// colors instead
+			if p.Gradient == "" {
+				p.FillP3 = css(p.Fill)
+			}
+			p.StrokeP3 = css(p.Stroke)
+			result[i] = p
+		}
+		return result
+	}
+	r.Paths = mapPaths(s.Paths)
+	r.Border = mapPaths(s.Border)
+	r.Texts = make([]Text, len(s.Texts))
+	for i, t := range s.Texts {
+		t.FillP3 = css(t.Fill)
+		r.Texts[i] = t
+	}
+	r.Gradients = make([]GradientFill, len(s.Gradients))
+	for i, g := range s.Gradients {
+		g.FromP3, g.ToP3 = css(g.From), css(g.To)
+		r.Gradients[i] = g
+	}
+	return &r
+}
+
+// inDisplayP3 returns a copy of the SVG with all colors as Display P3
+// coordinates, for rasterizing into a P3 image.
+func (s *SVG) inDisplayP3() *SVG {
+	r := *s
+	convert := func(hex string) string {
+		if p3, err := s.displayP3(hex); err == nil {
+			return p3.Hex()
+		}
+		return hex
+	}
+	mapPaths := func(paths Paths) Paths {
+		result := make(Paths, len(paths))
+		for i, p := range paths {
+			if p.Fill != "none" {
+				p.Fill = convert(p.Fill)
+			}
+			if p.Stroke != "" {
+				p.Stroke = convert(p.Stroke)
+			}
+			result[i] = p
+		}
+		return result
+	}
+	r.Paths = mapPaths(s.Paths)
+	r.Border = mapPaths(s.Border)
+	r.Texts = make([]Text, len(s.Texts))
+	for i, t := range s.Texts {
+		t.outline.Fill = convert(t.outline.Fill)
+		r.Texts[i] = t
+	}
+	r.Filters = make([]Filter, len(s.Filters))
+	for i, f := range s.Filters {
+		f.Color = convert(f.Color)
+		r.Filters[i] = f
+	}
+	r.Gradients = make([]GradientFill, len(s.Gradients))
+	for i, g := range s.Gradients {
+		g.From, g.To = convert(g.From), convert(g.To)
+		r.Gradients[i] = g
+	}
+	return &r
+}
+
+// encodePNG writes the image as PNG, tagged with the Display P3 profile for
+// wide gamut SVGs.
+func encodePNG(w io.Writer, img image.Image, wide bool) error {
+	if !wide {
+		return png.Encode(w, img)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	var profile bytes.Buffer
+	profile.WriteString("Display P3\x00\x00")
+	z := zlib.NewWriter(&profile)
+	if _, err := z.Write(displayP3Profile()); err != nil {
+		return err
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+	// the iCCP chunk must come before the image data, right after IHDR
+	data := buf.Bytes()
+	const ihdrEnd = 8 + 8 + 13 + 4
+	if _, err := w.Write(data[:ihdrEnd]); err != nil {
+		return err
+	}
+	if err := writePNGChunk(w, "iCCP", profile.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(data[ihdrEnd:])
+	return err
+}
+
+func writePNGChunk(w io.Writer, kind string, data []byte) error {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.WriteString(kind)
+	buf.Write(data)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()[4:]))
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// displayP3Profile returns a minimal ICC v4 display profile of Display P3.
+func displayP3Profile() []byte {
+	s15 := func(buf *bytes.Buffer, values ...float64) {
+		for _, v := range values {
+			_ = binary.Write(buf, binary.BigEndian, int32(math.Round(v*65536)))
+		}
+	}
+	xyz := func(x, y, z float64) []byte {
+		var b bytes.Buffer
+		b.WriteString("XYZ \x00\x00\x00\x00")
+		s15(&b, x, y, z)
+		return b.Bytes()
+	}
+	mluc := func(text string) []byte {
+		var b bytes.Buffer
+		units := utf16.Encode([]rune(text))
+		b.WriteString("mluc\x00\x00\x00\x00")
+		_ = binary.Write(&b, binary.BigEndian, []uint32{1, 12})
+		b.WriteString("enUS")
+		_ = binary.Write(&b, binary.BigEndian, []uint32{uint32(2 * len(units)), 28})
+		_ = binary.Write(&b, binary.BigEndian, units)
+		return b.Bytes()
+	}
+	var trc bytes.Buffer
+	// the sRGB transfer function as parametric curve
+	trc.WriteString("para\x00\x00\x00\x00\x00\x03\x00\x00")
+	s15(&trc, 2.4, 1/1.055, 0.055/1.055, 1/12.92, 0.04045)
+	var chad bytes.Buffer
+	// Bradford adaptation from D65 to D50
+	chad.WriteString("sf32\x00\x00\x00\x00")
+	s15(&chad,
+		1.0478112, 0.0228866, -0.0501270,
+		0.0295424, 0.9904844, -0.0170491,
+		-0.0092345, 0.0150436, 0.7521316,
+	)
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", mluc("Display P3")},
+		{"cprt", mluc("No copyright, use freely")},
+		{"wtpt", xyz(0.9642, 1, 0.8249)},
+		{"chad", chad.Bytes()},
+		{"rXYZ", xyz(0.515121, 0.241196, -0.001053)},
+		{"gXYZ", xyz(0.291977, 0.692245, 0.041885)},
+		{"bXYZ", xyz(0.157104, 0.066574, 0.784073)},
+		{"rTRC", trc.Bytes()},
+		{"gTRC", trc.Bytes()},
+		{"bTRC", trc.Bytes()},
+	}
+
+	var table, data bytes.Buffer
+	offset := 128 + 4 + 12*len(tags)
+	_ = binary.Write(&table, binary.BigEndian, uint32(len(tags)))
+	for _, t := range tags {
+		_ = binary.Write(&table, binary.BigEndian, []byte(t.sig))
+		_ = binary.Write(&table, binary.BigEndian, []uint32{uint32(offset + data.Len()), uint32(len(t.data))})
+		data.Write(t.data)
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+	}
+
+	var header bytes.Buffer
+	_ = binary.Write(&header, binary.BigEndian, uint32(offset+data.Len()))
+	header.WriteString("\x00\x00\x00\x00")
+	_ = binary.Write(&header, binary.BigEndian, uint32(0x04300000))
+	header.WriteString("mntrRGB XYZ ")
+	_ = binary.Write(&header, binary.BigEndian, []uint16{2024, 1, 1, 0, 0, 0})
+	header.WriteString("acsp")
+	header.Write(make([]byte, 24))
+	// perceptual rendering intent and the D50 illuminant of the PCS
+	_ = binary.Write(&header, binary.BigEndian, uint32(0))
+	s15(&header, 0.9642, 1, 0.8249)
+	header.Write(make([]byte, 128-header.Len()))
+
+	return append(append(header.Bytes(), table.Bytes()...), data.Bytes()...)
+}
@@ -0,0 +1,76 @@
+package jdenticon
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image/png"
+	"io/ioutil"
+	"testing"
+)
+
+func TestEncodePNGWide(t *testing.T) {
+	c := *DefaultConfig
+	c.WideGamut = true
+	var buf bytes.Buffer
+	if err := NewWithConfig("wide", &c).(Encoder).PNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != c.Width || b.Dy() != c.Height {
+		t.Errorf("image is %v, want %dx%d", b, c.Width, c.Height)
+	}
+
+	type chunk struct {
+		kind string
+		data []byte
+	}
+	var chunks []chunk
+	for rest := data[8:]; len(rest) > 0; {
+		if len(rest) < 12 {
+			t.Fatalf("truncated chunk of %d bytes", len(rest))
+		}
+		n := int(binary.BigEndian.Uint32(rest))
+		if len(rest) < 12+n {
+			t.Fatalf("chunk of %d bytes in %d", n, len(rest))
+		}
+		kind := string(rest[4:8])
+		if crc := binary.BigEndian.Uint32(rest[8+n:]); crc != crc32.ChecksumIEEE(rest[4:8+n]) {
+			t.Errorf("%s: CRC %08x, want %08x", kind, crc, crc32.ChecksumIEEE(rest[4:8+n]))
+		}
+		chunks = append(chunks, chunk{kind, rest[8 : 8+n]})
+		rest = rest[12+n:]
+	}
+	if len(chunks) < 3 || chunks[0].kind != "IHDR" || chunks[1].kind != "iCCP" {
+		t.Fatalf("chunks start with %v, want IHDR and iCCP", chunks)
+	}
+
+	// profile name, null separator and compression method 0
+	iccp := chunks[1].data
+	name := []byte("Display P3\x00\x00")
+	if !bytes.HasPrefix(iccp, name) {
+		t.Fatalf("iCCP starts with %q, want %q", iccp[:len(name)], name)
+	}
+	z, err := zlib.NewReader(bytes.NewReader(iccp[len(name):]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := ioutil.ReadAll(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile) < 128 {
+		t.Fatalf("profile of %d bytes, shorter than its header", len(profile))
+	}
+	if size := binary.BigEndian.Uint32(profile); int(size) != len(profile) {
+		t.Errorf("profile size field %d, want %d", size, len(profile))
+	}
+	if sig := string(profile[36:40]); sig != "acsp" {
+		t.Errorf("profile signature %q, want acsp", sig)
+	}
+}